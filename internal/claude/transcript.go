@@ -0,0 +1,273 @@
+package claude
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+)
+
+// Usage is the token usage of one or more conversations.
+type Usage struct {
+	Input         int
+	Output        int
+	CacheRead     int
+	CacheCreation int
+}
+
+// Total returns all tokens counted in u.
+func (u Usage) Total() int {
+	return u.Input + u.Output + u.CacheRead + u.CacheCreation
+}
+
+var unsafePathChars = regexp.MustCompile(`[^a-zA-Z0-9]`)
+
+// ProjectDir returns the directory where Claude keeps the transcripts of
+// conversations started in path: ~/.claude/projects/<path with every
+// non-alphanumeric character replaced by "-">. CLAUDE_CONFIG_DIR overrides
+// ~/.claude.
+func ProjectDir(path string) (string, error) {
+	root := os.Getenv("CLAUDE_CONFIG_DIR")
+	if root == "" {
+		home, err := os.UserHomeDir()
+		if err != nil {
+			return "", err
+		}
+		root = filepath.Join(home, ".claude")
+	}
+	return filepath.Join(root, "projects", unsafePathChars.ReplaceAllString(path, "-")), nil
+}
+
+// transcriptLine is the part of a transcript entry that carries usage.
+type transcriptLine struct {
+	Type    string `json:"type"`
+	Message struct {
+		ID    string `json:"id"`
+		Usage struct {
+			Input         int `json:"input_tokens"`
+			Output        int `json:"output_tokens"`
+			CacheRead     int `json:"cache_read_input_tokens"`
+			CacheCreation int `json:"cache_creation_input_tokens"`
+		} `json:"usage"`
+	} `json:"message"`
+}
+
+// UsageFor sums the token usage of every conversation started in path. A
+// worktree with no transcripts has zero usage.
+func UsageFor(path string) (Usage, error) {
+	var u Usage
+	dir, err := ProjectDir(path)
+	if err != nil {
+		return u, err
+	}
+	files, err := filepath.Glob(filepath.Join(dir, "*.jsonl"))
+	if err != nil {
+		return u, err
+	}
+	// A response split over several content blocks repeats its usage on each
+	// entry, so count every message ID once.
+	seen := map[string]bool{}
+	for _, f := range files {
+		if err := addUsage(&u, f, seen); err != nil {
+			return u, err
+		}
+	}
+	return u, nil
+}
+
+func addUsage(u *Usage, file string, seen map[string]bool) error {
+	f, err := os.Open(file)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	sc := bufio.NewScanner(f)
+	sc.Buffer(make([]byte, 0, 64*1024), 16*1024*1024)
+	for sc.Scan() {
+		var l transcriptLine
+		if json.Unmarshal(sc.Bytes(), &l) != nil || l.Type != "assistant" {
+			continue
+		}
+		if id := l.Message.ID; id != "" {
+			if seen[id] {
+				continue
+			}
+			seen[id] = true
+		}
+		u.Input += l.Message.Usage.Input
+		u.Output += l.Message.Usage.Output
+		u.CacheRead += l.Message.Usage.CacheRead
+		u.CacheCreation += l.Message.Usage.CacheCreation
+	}
+	return sc.Err()
+}
+
+// LatestConversation returns the ID of the most recently active conversation
+// started in path, or "" if there is none.
+func LatestConversation(path string) (string, error) {
+	file, _, err := latestTranscript(path)
+	if err != nil || file == "" {
+		return "", err
+	}
+	return strings.TrimSuffix(filepath.Base(file), ".jsonl"), nil
+}
+
+// LastActive returns when a conversation started in path was last written
+// to, or the zero time if there is none.
+func LastActive(path string) (time.Time, error) {
+	_, mod, err := latestTranscript(path)
+	return mod, err
+}
+
+func latestTranscript(path string) (string, time.Time, error) {
+	dir, err := ProjectDir(path)
+	if err != nil {
+		return "", time.Time{}, err
+	}
+	files, err := filepath.Glob(filepath.Join(dir, "*.jsonl"))
+	if err != nil {
+		return "", time.Time{}, err
+	}
+	var latest string
+	var latestMod time.Time
+	for _, f := range files {
+		info, err := os.Stat(f)
+		if err != nil {
+			continue
+		}
+		if info.ModTime().After(latestMod) {
+			latest, latestMod = f, info.ModTime()
+		}
+	}
+	return latest, latestMod, nil
+}
+
+// Conversation describes a conversation recorded for a project.
+type Conversation struct {
+	ID          string
+	Modified    time.Time // last write to its transcript
+	Size        int64     // transcript size in bytes
+	FirstPrompt string    // first thing the user typed; empty if none yet
+}
+
+// promptLine is the part of a transcript entry that carries a user prompt.
+type promptLine struct {
+	Type      string `json:"type"`
+	IsMeta    bool   `json:"isMeta"`
+	Sidechain bool   `json:"isSidechain"`
+	Message   struct {
+		Content json.RawMessage `json:"content"`
+	} `json:"message"`
+}
+
+// Conversations returns the conversations started in path, most recently
+// active first.
+func Conversations(path string) ([]Conversation, error) {
+	dir, err := ProjectDir(path)
+	if err != nil {
+		return nil, err
+	}
+	files, err := filepath.Glob(filepath.Join(dir, "*.jsonl"))
+	if err != nil {
+		return nil, err
+	}
+	var convs []Conversation
+	for _, f := range files {
+		info, err := os.Stat(f)
+		if err != nil {
+			continue
+		}
+		convs = append(convs, Conversation{
+			ID:          strings.TrimSuffix(filepath.Base(f), ".jsonl"),
+			Modified:    info.ModTime(),
+			Size:        info.Size(),
+			FirstPrompt: firstPrompt(f),
+		})
+	}
+	sort.Slice(convs, func(i, j int) bool { return convs[i].Modified.After(convs[j].Modified) })
+	return convs, nil
+}
+
+// firstPrompt returns the first prompt the user typed in a transcript,
+// skipping Claude's own bookkeeping and slash-command output.
+func firstPrompt(file string) string {
+	f, err := os.Open(file)
+	if err != nil {
+		return ""
+	}
+	defer f.Close()
+
+	sc := bufio.NewScanner(f)
+	sc.Buffer(make([]byte, 0, 64*1024), 16*1024*1024)
+	for sc.Scan() {
+		var l promptLine
+		if json.Unmarshal(sc.Bytes(), &l) != nil || l.Type != "user" || l.IsMeta || l.Sidechain {
+			continue
+		}
+		text := contentText(l.Message.Content)
+		if text == "" || strings.HasPrefix(text, "<") {
+			continue
+		}
+		return text
+	}
+	return ""
+}
+
+// contentText returns the text of a message's content, which is either a
+// string or a list of blocks; tool results carry no text.
+func contentText(raw json.RawMessage) string {
+	var s string
+	if json.Unmarshal(raw, &s) == nil {
+		return strings.TrimSpace(s)
+	}
+	var blocks []struct {
+		Type string `json:"type"`
+		Text string `json:"text"`
+	}
+	if json.Unmarshal(raw, &blocks) != nil {
+		return ""
+	}
+	for _, b := range blocks {
+		if b.Type == "text" && strings.TrimSpace(b.Text) != "" {
+			return strings.TrimSpace(b.Text)
+		}
+	}
+	return ""
+}
+
+// CopyConversation copies the transcript of conversation id from the
+// project of path from to that of path to, so `claude --resume id` can
+// continue it there. The working directory recorded in the transcript is
+// rewritten to to.
+func CopyConversation(from, to, id string) error {
+	src, err := ProjectDir(from)
+	if err != nil {
+		return err
+	}
+	dst, err := ProjectDir(to)
+	if err != nil {
+		return err
+	}
+	data, err := os.ReadFile(filepath.Join(src, id+".jsonl"))
+	if err != nil {
+		return fmt.Errorf("read conversation: %w", err)
+	}
+	oldCwd, _ := json.Marshal(from)
+	newCwd, _ := json.Marshal(to)
+	data = bytes.ReplaceAll(data, append([]byte(`"cwd":`), oldCwd...), append([]byte(`"cwd":`), newCwd...))
+
+	if err := os.MkdirAll(dst, 0755); err != nil {
+		return fmt.Errorf("mkdir: %w", err)
+	}
+	if err := os.WriteFile(filepath.Join(dst, id+".jsonl"), data, 0644); err != nil {
+		return fmt.Errorf("write conversation: %w", err)
+	}
+	return nil
+}
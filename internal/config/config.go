@@ -0,0 +1,250 @@
+package config
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// RepoFile is the per-repo config file name, looked up at the repo root.
+const RepoFile = ".deckard.json"
+
+// Config holds Deckard settings. Values are layered: built-in defaults, then
+// the user config (~/.config/deckard/config.json), then the repo's .deckard.json.
+type Config struct {
+	CI     CI     `json:"ci"`
+	Retire Retire `json:"retire"`
+	Trash  Trash  `json:"trash"`
+	Setup  Setup  `json:"setup"`
+
+	Restore Restore `json:"restore"`
+
+	Spawn    Spawn    `json:"spawn"`
+	Variants Variants `json:"variants"`
+	Tmux     Tmux     `json:"tmux"`
+
+	// Layout lists the windows opened next to the agent in every new
+	// session, e.g. a shell and a dev server.
+	Layout []Window `json:"layout"`
+
+	Ports Ports `json:"ports"`
+
+	// Agents are named agent profiles; DefaultAgent is used when a session
+	// doesn't name one.
+	Agents       map[string]Agent `json:"agents"`
+	DefaultAgent string           `json:"default_agent"`
+
+	// Prompts is a library of task prompt templates, keyed by name. Templates
+	// may use {{branch}}, {{ticket}} and {{base}}.
+	Prompts map[string]string `json:"prompts"`
+}
+
+// CI controls the "fix CI" action that hands failing job logs to the agent.
+type CI struct {
+	LogTailLines int  `json:"log_tail_lines"` // lines kept from each failing job log
+	AutoFix      bool `json:"auto_fix"`       // send a fix prompt automatically when a pipeline fails
+	MaxRetries   int  `json:"max_retries"`    // automatic fix attempts per branch before giving up
+}
+
+// Retire controls clean-up of worktrees whose MR has merged.
+type Retire struct {
+	Auto bool `json:"auto"` // retire merged worktrees automatically on refresh
+}
+
+// Restore controls bringing back the sessions that were running when the
+// tmux server went away, e.g. after a reboot.
+type Restore struct {
+	Auto bool `json:"auto"` // restore them at startup instead of offering to
+}
+
+// Trash controls how long archived worktrees are kept.
+type Trash struct {
+	RetentionDays int `json:"retention_days"` // purge archives older than this; 0 keeps them forever
+}
+
+// Setup lists the chores run in every new worktree before its agent starts.
+// Paths are relative to the repo root (source) and the worktree (destination).
+type Setup struct {
+	Copy    []string  `json:"copy"`    // files or directories copied, e.g. ".env.local"
+	Symlink []string  `json:"symlink"` // files or directories linked, e.g. "node_modules/.cache"
+	Run     []Command `json:"run"`     // shell commands run in order in the worktree
+}
+
+// Command is a shell command with a timeout.
+type Command struct {
+	Cmd            string `json:"cmd"`
+	TimeoutSeconds int    `json:"timeout_seconds"` // 0 means DefaultCommandTimeout
+}
+
+// DefaultCommandTimeout bounds setup commands that don't set their own timeout.
+const DefaultCommandTimeout = 10 * time.Minute
+
+// Timeout returns the effective timeout for c.
+func (c Command) Timeout() time.Duration {
+	if c.TimeoutSeconds > 0 {
+		return time.Duration(c.TimeoutSeconds) * time.Second
+	}
+	return DefaultCommandTimeout
+}
+
+// Empty reports whether there is nothing to set up.
+func (s Setup) Empty() bool {
+	return len(s.Copy) == 0 && len(s.Symlink) == 0 && len(s.Run) == 0
+}
+
+// Agent is a named agent profile: the command a session's tmux pane runs.
+type Agent struct {
+	Command []string `json:"command"`
+}
+
+// Spawn controls batch session creation from a task file.
+type Spawn struct {
+	Concurrency int `json:"concurrency"` // sessions created in parallel
+}
+
+// Variants controls best-of-N runs: one task given to several worktrees.
+type Variants struct {
+	Count       int     `json:"count"`        // worktrees created by default
+	TestCommand Command `json:"test_command"` // run in each variant by the compare view
+}
+
+// Tmux controls the tmux settings Deckard manages for its sessions.
+type Tmux struct {
+	DetachKey string `json:"detach_key"` // no-prefix key that returns to the dashboard, in tmux syntax
+	Mouse     bool   `json:"mouse"`      // mouse scrolling and selection
+	Status    string `json:"status"`     // status bar: "full" (waiting count and keys), "minimal" (waiting count) or "off"
+}
+
+// Window is a tmux window of the session layout. Cmd runs in the worktree via
+// the shell; empty means an interactive shell. Panes are split off the window,
+// each with its own command.
+type Window struct {
+	Name  string   `json:"name"`
+	Cmd   string   `json:"cmd"`
+	Panes []string `json:"panes"`
+}
+
+// Ports controls the block of ports reserved for each worktree, so dev
+// servers in different worktrees don't collide. Blocks come from the pool
+// From–To, which all repos share.
+type Ports struct {
+	From        int      `json:"from"`
+	To          int      `json:"to"`
+	PerWorktree int      `json:"per_worktree"` // ports in each block
+	Env         []string `json:"env"`          // variables set to the block's ports, in order
+}
+
+// Environ returns the variables for the block starting at start: each name
+// in Env set to its port, plus DECKARD_PORT (the first port) and
+// DECKARD_PORT_COUNT, from which scripts can work out the rest.
+func (p Ports) Environ(start int) []string {
+	env := []string{
+		fmt.Sprintf("DECKARD_PORT=%d", start),
+		fmt.Sprintf("DECKARD_PORT_COUNT=%d", p.PerWorktree),
+	}
+	for i, name := range p.Env {
+		if i >= p.PerWorktree {
+			break
+		}
+		env = append(env, fmt.Sprintf("%s=%d", name, start+i))
+	}
+	return env
+}
+
+// AgentCommand returns the command for the named profile, or for the default
+// profile when name is empty.
+func (c Config) AgentCommand(name string) ([]string, error) {
+	if name == "" {
+		name = c.DefaultAgent
+	}
+	a, ok := c.Agents[name]
+	if !ok || len(a.Command) == 0 {
+		return nil, fmt.Errorf("unknown agent profile %q", name)
+	}
+	return a.Command, nil
+}
+
+// DefaultAgentCommand returns the default profile's command, falling back to
+// the built-in profile when default_agent names none.
+func (c Config) DefaultAgentCommand() []string {
+	if command, err := c.AgentCommand(""); err == nil {
+		return command
+	}
+	command, _ := Default().AgentCommand("")
+	return command
+}
+
+// Default returns the built-in configuration.
+func Default() Config {
+	return Config{
+		CI: CI{
+			LogTailLines: 80,
+			MaxRetries:   2,
+		},
+		Trash: Trash{
+			RetentionDays: 30,
+		},
+		Spawn: Spawn{
+			Concurrency: 4,
+		},
+		Variants: Variants{
+			Count: 3,
+		},
+		Ports: Ports{
+			From:        4000,
+			To:          4999,
+			PerWorktree: 10,
+			Env:         []string{"PORT"},
+		},
+		Tmux: Tmux{
+			DetachKey: "C-]",
+			Mouse:     true,
+			Status:    "full",
+		},
+		Agents: map[string]Agent{
+			"claude": {Command: []string{"claude", "--dangerously-skip-permissions"}},
+		},
+		DefaultAgent: "claude",
+	}
+}
+
+// Load returns the effective configuration for the repo at repoRoot.
+// Missing files are not an error; malformed files are.
+func Load(repoRoot string) (Config, error) {
+	cfg := Default()
+
+	if dir, err := os.UserConfigDir(); err == nil {
+		if err := merge(&cfg, filepath.Join(dir, "deckard", "config.json")); err != nil {
+			return cfg, err
+		}
+	}
+	if repoRoot != "" {
+		if err := merge(&cfg, filepath.Join(repoRoot, RepoFile)); err != nil {
+			return cfg, err
+		}
+	}
+	// An empty key would make tmux reject the whole managed config.
+	if strings.TrimSpace(cfg.Tmux.DetachKey) == "" {
+		cfg.Tmux.DetachKey = Default().Tmux.DetachKey
+	}
+	return cfg, nil
+}
+
+// merge decodes the file at path over cfg, leaving fields it omits untouched.
+func merge(cfg *Config, path string) error {
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("read %s: %w", path, err)
+	}
+	if err := json.Unmarshal(data, cfg); err != nil {
+		return fmt.Errorf("parse %s: %w", path, err)
+	}
+	return nil
+}
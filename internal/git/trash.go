@@ -0,0 +1,171 @@
+package git
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+)
+
+// TrashRefPrefix is the ref namespace archived worktrees are kept under, so
+// their commits stay reachable after the branch is deleted.
+const TrashRefPrefix = "refs/deckard/trash/"
+
+// Snapshot records the full working tree at path — staged, unstaged and
+// untracked files, honouring .gitignore — as a commit whose parent is HEAD.
+// The worktree, its index and the stash list are left untouched.
+// Returns "" if the working tree matches HEAD.
+func Snapshot(path string) (string, error) {
+	tmp, err := os.MkdirTemp("", "deckard-snapshot-")
+	if err != nil {
+		return "", fmt.Errorf("temp dir: %w", err)
+	}
+	defer os.RemoveAll(tmp)
+
+	env := append(os.Environ(), "GIT_INDEX_FILE="+filepath.Join(tmp, "index"))
+	run := func(args ...string) (string, error) {
+		cmd := exec.Command("git", append([]string{"-C", path}, args...)...)
+		cmd.Env = env
+		out, err := cmd.CombinedOutput()
+		if err != nil {
+			return "", fmt.Errorf("git %s: %s", args[0], strings.TrimSpace(string(out)))
+		}
+		return strings.TrimSpace(string(out)), nil
+	}
+
+	if _, err := run("read-tree", "HEAD"); err != nil {
+		return "", err
+	}
+	if _, err := run("add", "-A"); err != nil {
+		return "", err
+	}
+	tree, err := run("write-tree")
+	if err != nil {
+		return "", err
+	}
+	head, err := run("rev-parse", "HEAD^{tree}")
+	if err != nil {
+		return "", err
+	}
+	if tree == head {
+		return "", nil
+	}
+	return run("commit-tree", tree, "-p", "HEAD", "-m", "deckard snapshot")
+}
+
+// ForkWorktree creates a worktree at .claude/worktrees/<slug> on a new
+// branch holding the current state of the worktree at src: the new branch
+// starts at src's HEAD and src's uncommitted changes are applied to it,
+// uncommitted. src is left untouched. The fork inherits the recorded base of
+// srcBranch and records srcBranch as its parent (see BranchParent).
+// Returns the path of the created worktree.
+func ForkWorktree(repoRoot, src, srcBranch, branch string) (string, error) {
+	tip, err := HeadCommit(src)
+	if err != nil {
+		return "", err
+	}
+	snapshot, err := Snapshot(src)
+	if err != nil {
+		return "", err
+	}
+
+	path := filepath.Join(repoRoot, ".claude", "worktrees", BranchToSlug(branch))
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return "", fmt.Errorf("mkdir: %w", err)
+	}
+	out, err := exec.Command("git", "-C", repoRoot, "worktree", "add", "--no-track", "-b", branch, path, tip).CombinedOutput()
+	if err != nil {
+		return "", fmt.Errorf("%s", strings.TrimSpace(string(out)))
+	}
+
+	if snapshot != "" {
+		if err := ApplySnapshot(path, tip, snapshot); err != nil {
+			return path, err
+		}
+	}
+	if base := BranchBase(src, srcBranch); base != "" {
+		if err := SetBranchBase(repoRoot, branch, base); err != nil {
+			return path, err
+		}
+	}
+	return path, SetBranchParent(repoRoot, branch, srcBranch)
+}
+
+// HeadCommit returns the commit checked out in the worktree at path.
+func HeadCommit(path string) (string, error) {
+	out, err := exec.Command("git", "-C", path, "rev-parse", "HEAD").Output()
+	if err != nil {
+		return "", fmt.Errorf("git rev-parse: %w", err)
+	}
+	return strings.TrimSpace(string(out)), nil
+}
+
+// SetRef points ref at commit, creating it if needed.
+func SetRef(repoRoot, ref, commit string) error {
+	out, err := exec.Command("git", "-C", repoRoot, "update-ref", ref, commit).CombinedOutput()
+	if err != nil {
+		return fmt.Errorf("update-ref: %s", strings.TrimSpace(string(out)))
+	}
+	return nil
+}
+
+// DeleteRef removes ref. A missing ref is not an error.
+func DeleteRef(repoRoot, ref string) error {
+	if exec.Command("git", "-C", repoRoot, "rev-parse", "--verify", "--quiet", ref).Run() != nil {
+		return nil
+	}
+	out, err := exec.Command("git", "-C", repoRoot, "update-ref", "-d", ref).CombinedOutput()
+	if err != nil {
+		return fmt.Errorf("update-ref -d: %s", strings.TrimSpace(string(out)))
+	}
+	return nil
+}
+
+// BranchExists reports whether a local branch exists.
+func BranchExists(repoRoot, branch string) bool {
+	return exec.Command("git", "-C", repoRoot, "rev-parse", "--verify", "--quiet", "refs/heads/"+branch).Run() == nil
+}
+
+// RestoreWorktree re-creates a worktree at path on branch. If the branch no
+// longer exists it is re-created at tip. When snapshot is set, the changes it
+// holds relative to tip are applied to the new working tree, uncommitted.
+func RestoreWorktree(repoRoot, path, branch, tip, snapshot string) error {
+	if _, err := os.Stat(path); err == nil {
+		return fmt.Errorf("%s already exists", path)
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return fmt.Errorf("mkdir: %w", err)
+	}
+
+	args := []string{"-C", repoRoot, "worktree", "add", path, branch}
+	if !BranchExists(repoRoot, branch) {
+		args = []string{"-C", repoRoot, "worktree", "add", "-b", branch, path, tip}
+	}
+	if out, err := exec.Command("git", args...).CombinedOutput(); err != nil {
+		return fmt.Errorf("%s", strings.TrimSpace(string(out)))
+	}
+
+	if snapshot == "" {
+		return nil
+	}
+	return ApplySnapshot(path, tip, snapshot)
+}
+
+// ApplySnapshot applies the difference between base and snapshot to the
+// working tree at path, leaving the changes uncommitted.
+func ApplySnapshot(path, base, snapshot string) error {
+	diff, err := exec.Command("git", "-C", path, "diff", "--binary", base, snapshot).Output()
+	if err != nil {
+		return fmt.Errorf("git diff: %w", err)
+	}
+	if len(diff) == 0 {
+		return nil
+	}
+	apply := exec.Command("git", "-C", path, "apply", "--whitespace=nowarn", "-")
+	apply.Stdin = strings.NewReader(string(diff))
+	if out, err := apply.CombinedOutput(); err != nil {
+		return fmt.Errorf("git apply: %s", strings.TrimSpace(string(out)))
+	}
+	return nil
+}
@@ -5,7 +5,9 @@ import (
 	"os"
 	"os/exec"
 	"path/filepath"
+	"strconv"
 	"strings"
+	"time"
 
 	"deckard/internal/model"
 )
@@ -19,9 +21,12 @@ func RepoRoot() (string, error) {
 	return strings.TrimSpace(string(out)), nil
 }
 
-// CreateWorktree creates a new worktree at .claude/worktrees/<slug> on a new branch.
+// CreateWorktree creates a new worktree at .claude/worktrees/<slug> on a new
+// branch starting at base. An empty base means origin/<default branch>; an
+// origin/ base is used as last fetched, so refresh it with FetchBase first.
+// The base is recorded against the branch (see BranchBase).
 // Returns the path of the created worktree.
-func CreateWorktree(repoRoot, branch string) (string, error) {
+func CreateWorktree(repoRoot, branch, base string) (string, error) {
 	slug := BranchToSlug(branch)
 	path := filepath.Join(repoRoot, ".claude", "worktrees", slug)
 
@@ -29,15 +34,262 @@ func CreateWorktree(repoRoot, branch string) (string, error) {
 		return "", fmt.Errorf("mkdir: %w", err)
 	}
 
-	cmd := exec.Command("git", "worktree", "add", path, "-b", branch)
+	base = ResolveBase(repoRoot, base)
+
+	// --no-track: the new branch must not push to, or pull from, its base.
+	cmd := exec.Command("git", "worktree", "add", "--no-track", "-b", branch, path, base)
 	cmd.Dir = repoRoot
 	if out, err := cmd.CombinedOutput(); err != nil {
 		return "", fmt.Errorf("%s", strings.TrimSpace(string(out)))
 	}
 
+	if err := SetBranchBase(repoRoot, branch, base); err != nil {
+		return path, err
+	}
 	return path, nil
 }
 
+// FetchBase fetches base from origin ahead of CreateWorktree; an empty base
+// means origin/<default branch>, and bases outside origin/ need no fetch. A
+// failure, e.g. when offline, leaves the last-fetched ref in place, so callers
+// can treat it as a warning and branch from that.
+func FetchBase(repoRoot, base string) error {
+	base = ResolveBase(repoRoot, base)
+	remote, ok := strings.CutPrefix(base, "origin/")
+	if !ok {
+		return nil
+	}
+	return Fetch(repoRoot, "+refs/heads/"+remote+":refs/remotes/"+base)
+}
+
+// ResolveBase returns the ref a new branch starts from: base, or
+// origin/<default branch> when base is empty.
+func ResolveBase(repoRoot, base string) string {
+	if base == "" {
+		return "origin/" + DefaultBranch(repoRoot)
+	}
+	return base
+}
+
+// DefaultBranch returns the name of origin's default branch, falling back to
+// "main" when origin/HEAD is not set.
+func DefaultBranch(repoRoot string) string {
+	out, err := exec.Command("git", "-C", repoRoot, "symbolic-ref", "--short", "refs/remotes/origin/HEAD").Output()
+	if err == nil {
+		return strings.TrimPrefix(strings.TrimSpace(string(out)), "origin/")
+	}
+	for _, name := range []string{"main", "master"} {
+		if exec.Command("git", "-C", repoRoot, "rev-parse", "--verify", "--quiet", "refs/remotes/origin/"+name).Run() == nil {
+			return name
+		}
+	}
+	return "main"
+}
+
+// SetBranchBase records the ref a branch was started from in the repo config
+// (branch.<name>.deckardbase), so ahead/behind can be measured against it.
+func SetBranchBase(repoRoot, branch, base string) error {
+	return setBranchConfig(repoRoot, branch, "deckardbase", base)
+}
+
+// BranchBase returns the recorded base of branch, or "" if none was recorded.
+// dir may be the repo root or any of its worktrees.
+func BranchBase(dir, branch string) string {
+	return branchConfig(dir, branch, "deckardbase")
+}
+
+// SetBranchVariant records that branch is one of a group of competing
+// attempts at the same task (branch.<name>.deckardvariant). An empty group
+// removes the branch from its group.
+func SetBranchVariant(repoRoot, branch, group string) error {
+	if group == "" {
+		return unsetBranchConfig(repoRoot, branch, "deckardvariant")
+	}
+	return setBranchConfig(repoRoot, branch, "deckardvariant", group)
+}
+
+// BranchVariant returns the variant group of branch, or "" if it has none.
+func BranchVariant(dir, branch string) string {
+	return branchConfig(dir, branch, "deckardvariant")
+}
+
+// SetBranchParent records the branch a fork was taken from
+// (branch.<name>.deckardparent).
+func SetBranchParent(repoRoot, branch, parent string) error {
+	return setBranchConfig(repoRoot, branch, "deckardparent", parent)
+}
+
+// BranchParent returns the branch that branch was forked from, or "" if it
+// is not a fork.
+func BranchParent(dir, branch string) string {
+	return branchConfig(dir, branch, "deckardparent")
+}
+
+func setBranchConfig(repoRoot, branch, key, value string) error {
+	out, err := exec.Command("git", "-C", repoRoot, "config", "branch."+branch+"."+key, value).CombinedOutput()
+	if err != nil {
+		return fmt.Errorf("git config: %s", strings.TrimSpace(string(out)))
+	}
+	return nil
+}
+
+func unsetBranchConfig(repoRoot, branch, key string) error {
+	cmd := exec.Command("git", "-C", repoRoot, "config", "--unset", "branch."+branch+"."+key)
+	out, err := cmd.CombinedOutput()
+	// Exit status 5 means the key was not set.
+	if exit, ok := err.(*exec.ExitError); ok && exit.ExitCode() == 5 {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("git config: %s", strings.TrimSpace(string(out)))
+	}
+	return nil
+}
+
+func branchConfig(dir, branch, key string) string {
+	out, err := exec.Command("git", "-C", dir, "config", "--get", "branch."+branch+"."+key).Output()
+	if err != nil {
+		return ""
+	}
+	return strings.TrimSpace(string(out))
+}
+
+// AheadBehind counts the commits HEAD of the worktree at path has that base
+// lacks (ahead), and the reverse (behind).
+func AheadBehind(path, base string) (ahead, behind int, err error) {
+	out, err := exec.Command("git", "-C", path, "rev-list", "--left-right", "--count", base+"...HEAD").Output()
+	if err != nil {
+		return 0, 0, fmt.Errorf("git rev-list: %w", err)
+	}
+	fmt.Sscanf(strings.TrimSpace(string(out)), "%d %d", &behind, &ahead)
+	return ahead, behind, nil
+}
+
+// CommitTime returns the committer date of HEAD in the worktree at path.
+func CommitTime(path string) (time.Time, error) {
+	out, err := exec.Command("git", "-C", path, "log", "-1", "--format=%ct").Output()
+	if err != nil {
+		return time.Time{}, fmt.Errorf("git log: %w", err)
+	}
+	secs, err := strconv.ParseInt(strings.TrimSpace(string(out)), 10, 64)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("git log: %w", err)
+	}
+	return time.Unix(secs, 0), nil
+}
+
+// DiffStat summarises the changes in the worktree at path since it forked
+// from base, uncommitted changes to tracked files included.
+type DiffStat struct {
+	Files   int
+	Added   int
+	Deleted int
+}
+
+// DiffAgainst returns the DiffStat of the worktree at path against the merge
+// base of base and HEAD.
+func DiffAgainst(path, base string) (DiffStat, error) {
+	var d DiffStat
+	mb, err := exec.Command("git", "-C", path, "merge-base", base, "HEAD").Output()
+	if err != nil {
+		return d, fmt.Errorf("git merge-base: %w", err)
+	}
+	out, err := exec.Command("git", "-C", path, "diff", "--numstat", strings.TrimSpace(string(mb))).Output()
+	if err != nil {
+		return d, fmt.Errorf("git diff: %w", err)
+	}
+	for _, line := range strings.Split(strings.TrimSpace(string(out)), "\n") {
+		if line == "" {
+			continue
+		}
+		// Binary files report "-" for both counts.
+		var added, deleted int
+		fmt.Sscanf(line, "%d\t%d", &added, &deleted)
+		d.Files++
+		d.Added += added
+		d.Deleted += deleted
+	}
+	return d, nil
+}
+
+// CheckoutWorktree creates a worktree at .claude/worktrees/<slug> for an
+// existing branch, fetching it from origin first. A local branch of the same
+// name is reused; otherwise a new local branch tracking origin is created.
+// If mrIID is set and origin has no such branch (e.g. an MR from a fork), the
+// MR head is fetched instead. A branch with no recorded base gets origin's
+// default branch as its base, so its progress can be measured. Returns the
+// path of the created worktree.
+func CheckoutWorktree(repoRoot, branch string, mrIID int) (string, error) {
+	slug := BranchToSlug(branch)
+	path := filepath.Join(repoRoot, ".claude", "worktrees", slug)
+
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return "", fmt.Errorf("mkdir: %w", err)
+	}
+
+	remoteRef := "refs/remotes/origin/" + branch
+	err := Fetch(repoRoot, "+refs/heads/"+branch+":"+remoteRef)
+	if err != nil && mrIID != 0 {
+		remoteRef = fmt.Sprintf("refs/remotes/origin/merge-requests/%d", mrIID)
+		err = Fetch(repoRoot, fmt.Sprintf("+refs/merge-requests/%d/head:%s", mrIID, remoteRef))
+	}
+	if err != nil && !BranchExists(repoRoot, branch) {
+		return "", err
+	}
+
+	args := []string{"worktree", "add", path, branch}
+	if !BranchExists(repoRoot, branch) {
+		args = []string{"worktree", "add"}
+		if remoteRef == "refs/remotes/origin/"+branch {
+			args = append(args, "--track")
+		}
+		args = append(args, "-b", branch, path, remoteRef)
+	}
+	cmd := exec.Command("git", args...)
+	cmd.Dir = repoRoot
+	if out, err := cmd.CombinedOutput(); err != nil {
+		return "", fmt.Errorf("%s", strings.TrimSpace(string(out)))
+	}
+
+	if BranchBase(repoRoot, branch) == "" {
+		if err := SetBranchBase(repoRoot, branch, "origin/"+DefaultBranch(repoRoot)); err != nil {
+			return path, err
+		}
+	}
+	return path, nil
+}
+
+// Fetch fetches the given refspecs from origin.
+func Fetch(repoRoot string, refspecs ...string) error {
+	args := append([]string{"-C", repoRoot, "fetch", "--quiet", "origin"}, refspecs...)
+	if out, err := exec.Command("git", args...).CombinedOutput(); err != nil {
+		return fmt.Errorf("git fetch: %s", strings.TrimSpace(string(out)))
+	}
+	return nil
+}
+
+// RemoteBranches fetches origin and returns its branch names, without the
+// "origin/" prefix.
+func RemoteBranches(repoRoot string) ([]string, error) {
+	if err := Fetch(repoRoot, "--prune"); err != nil {
+		return nil, err
+	}
+	out, err := exec.Command("git", "-C", repoRoot, "for-each-ref",
+		"--sort=-committerdate", "--format=%(refname)", "refs/remotes/origin/").Output()
+	if err != nil {
+		return nil, fmt.Errorf("git for-each-ref: %w", err)
+	}
+	var branches []string
+	for _, ref := range strings.Split(strings.TrimSpace(string(out)), "\n") {
+		name := strings.TrimPrefix(ref, "refs/remotes/origin/")
+		if name == "" || name == "HEAD" || strings.HasPrefix(name, "merge-requests/") {
+			continue
+		}
+		branches = append(branches, name)
+	}
+	return branches, nil
+}
+
 // ListWorktrees runs git worktree list --porcelain and returns parsed sessions.
 func ListWorktrees() ([]model.Session, error) {
 	out, err := exec.Command("git", "worktree", "list", "--porcelain").Output()
@@ -88,18 +340,103 @@ func parseBlock(block string) *model.Session {
 }
 
 // DeleteWorktree removes the worktree at path and attempts to delete the branch.
-// The repoRoot is used as the working directory for git commands.
-func DeleteWorktree(repoRoot, path, branch string) error {
-	cmd := exec.Command("git", "worktree", "remove", path)
+// The repoRoot is used as the working directory for git commands. With force,
+// uncommitted changes are discarded and the branch is deleted even if unmerged.
+func DeleteWorktree(repoRoot, path, branch string, force bool) error {
+	args := []string{"worktree", "remove", path}
+	if force {
+		args = append(args, "--force")
+	}
+	cmd := exec.Command("git", args...)
 	cmd.Dir = repoRoot
 	if out, err := cmd.CombinedOutput(); err != nil {
 		return fmt.Errorf("%s", strings.TrimSpace(string(out)))
 	}
 	// Best-effort branch deletion — ignore errors (e.g. branch not fully merged).
-	exec.Command("git", "-C", repoRoot, "branch", "-d", branch).Run()
+	DeleteBranch(repoRoot, branch, force)
+	return nil
+}
+
+// DeleteBranch deletes a local branch. Without force, git refuses to delete a
+// branch that is not merged into HEAD, which squash-merged MRs never are.
+func DeleteBranch(repoRoot, branch string, force bool) error {
+	flag := "-d"
+	if force {
+		flag = "-D"
+	}
+	out, err := exec.Command("git", "-C", repoRoot, "branch", flag, branch).CombinedOutput()
+	if err != nil {
+		return fmt.Errorf("%s", strings.TrimSpace(string(out)))
+	}
 	return nil
 }
 
+// WorkState summarises work in a worktree that removing it could lose.
+type WorkState struct {
+	Uncommitted int // modified, staged or untracked files
+	Unpushed    int // commits on HEAD not reachable from any remote branch, the base or merged
+	Stashes     int // stash entries created on the worktree's branch
+}
+
+// Clean reports whether the worktree can be removed without losing work.
+// Stashes are not counted: they live in the shared repo and survive removal.
+func (w WorkState) Clean() bool {
+	return w.Uncommitted == 0 && w.Unpushed == 0
+}
+
+// Uncommitted counts the modified, staged and untracked files in the
+// worktree at path.
+func Uncommitted(path string) (int, error) {
+	out, err := exec.Command("git", "-C", path, "status", "--porcelain").Output()
+	if err != nil {
+		return 0, fmt.Errorf("git status: %w", err)
+	}
+	trimmed := strings.TrimSpace(string(out))
+	if trimmed == "" {
+		return 0, nil
+	}
+	return len(strings.Split(trimmed, "\n")), nil
+}
+
+// InspectWork reports uncommitted, unpushed and stashed work in the worktree
+// at path, which has branch checked out. Commits reachable from a remote
+// branch, the branch's recorded base, origin's default branch or merged (the
+// head a merged MR covered; "" if none) are not counted as unpushed, so a
+// squash-merged branch whose remote was deleted is still clean.
+func InspectWork(path, branch, merged string) (WorkState, error) {
+	var w WorkState
+	var err error
+	if w.Uncommitted, err = Uncommitted(path); err != nil {
+		return w, err
+	}
+
+	args := []string{"-C", path, "rev-list", "--count", "HEAD", "--not", "--remotes"}
+	for _, ref := range []string{BranchBase(path, branch), "origin/" + DefaultBranch(path), merged} {
+		// rev-list fails on unknown revisions, e.g. a merged head never fetched.
+		if ref != "" && exec.Command("git", "-C", path, "rev-parse", "--verify", "--quiet", ref+"^{commit}").Run() == nil {
+			args = append(args, ref)
+		}
+	}
+	out, err := exec.Command("git", args...).Output()
+	if err != nil {
+		return w, fmt.Errorf("git rev-list: %w", err)
+	}
+	fmt.Sscanf(strings.TrimSpace(string(out)), "%d", &w.Unpushed)
+
+	// Stash subjects read "WIP on <branch>: …" or "On <branch>: …".
+	out, err = exec.Command("git", "-C", path, "stash", "list", "--format=%gs").Output()
+	if err != nil {
+		return w, fmt.Errorf("git stash list: %w", err)
+	}
+	for _, line := range strings.Split(string(out), "\n") {
+		if strings.HasPrefix(line, "WIP on "+branch+":") || strings.HasPrefix(line, "On "+branch+":") {
+			w.Stashes++
+		}
+	}
+
+	return w, nil
+}
+
 // BranchToSlug normalises a branch name into a filesystem/tmux-safe slug.
 func BranchToSlug(branch string) string {
 	if branch == "" {
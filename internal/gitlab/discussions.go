@@ -0,0 +1,78 @@
+package gitlab
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"deckard/internal/model"
+)
+
+// glabDiscussion mirrors the fields we care about from the MR discussions API.
+type glabDiscussion struct {
+	ID    string `json:"id"`
+	Notes []struct {
+		Body   string `json:"body"`
+		System bool   `json:"system"`
+		Author struct {
+			Username string `json:"username"`
+		} `json:"author"`
+		Resolvable bool `json:"resolvable"`
+		Resolved   bool `json:"resolved"`
+		Position   *struct {
+			NewPath string `json:"new_path"`
+			NewLine int    `json:"new_line"`
+			OldPath string `json:"old_path"`
+			OldLine int    `json:"old_line"`
+		} `json:"position"`
+	} `json:"notes"`
+}
+
+// FetchUnresolvedThreads returns the resolvable, unresolved discussion threads
+// on the merge request with the given IID.
+func FetchUnresolvedThreads(iid int) ([]model.Thread, error) {
+	out, err := api("GET", fmt.Sprintf("projects/:id/merge_requests/%d/discussions?per_page=100", iid))
+	if err != nil {
+		return nil, err
+	}
+	var ds []glabDiscussion
+	if err := json.Unmarshal(out, &ds); err != nil {
+		return nil, fmt.Errorf("decode discussions: %w", err)
+	}
+
+	var threads []model.Thread
+	for _, d := range ds {
+		if len(d.Notes) == 0 {
+			continue
+		}
+		first := d.Notes[0]
+		if first.System || !first.Resolvable || first.Resolved {
+			continue
+		}
+		t := model.Thread{
+			ID:     d.ID,
+			Author: first.Author.Username,
+			Body:   first.Body,
+		}
+		if p := first.Position; p != nil {
+			t.File, t.Line = p.NewPath, p.NewLine
+			if t.Line == 0 {
+				// comment on a removed line
+				t.File, t.Line = p.OldPath, p.OldLine
+			}
+		}
+		for _, n := range d.Notes[1:] {
+			if n.System {
+				continue
+			}
+			t.Notes = append(t.Notes, model.Note{Author: n.Author.Username, Body: n.Body})
+		}
+		threads = append(threads, t)
+	}
+	return threads, nil
+}
+
+// ResolveThread marks a discussion thread on the merge request as resolved.
+func ResolveThread(iid int, threadID string) error {
+	_, err := api("PUT", fmt.Sprintf("projects/:id/merge_requests/%d/discussions/%s?resolved=true", iid, threadID))
+	return err
+}
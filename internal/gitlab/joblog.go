@@ -0,0 +1,116 @@
+package gitlab
+
+import (
+	"regexp"
+	"strings"
+
+	"deckard/internal/model"
+)
+
+var (
+	// section_start:1560896352:step_script[collapsed=true]\r\e[0KHeader text
+	sectionMarker = regexp.MustCompile(`section_(start|end):\d+:([A-Za-z0-9_.\-]+)(\[[^\]]*\])?\r?\x1b\[0K`)
+	ansiEscape    = regexp.MustCompile(`\x1b\[[0-9;?]*[A-Za-z]`)
+)
+
+// runnerSections are emitted by the GitLab runner itself rather than the job's
+// script, so they are never blamed for a failure.
+var runnerSections = map[string]bool{
+	"resolve_secrets":             true,
+	"prepare_executor":            true,
+	"prepare_script":              true,
+	"get_sources":                 true,
+	"restore_cache":               true,
+	"download_artifacts":          true,
+	"archive_cache":               true,
+	"archive_cache_on_failure":    true,
+	"upload_artifacts_on_success": true,
+	"upload_artifacts_on_failure": true,
+	"cleanup_file_variables":      true,
+}
+
+// ParseJobLog splits a raw job trace into sections with ANSI escapes removed.
+// When failed is true the last script section is flagged as Failing.
+func ParseJobLog(raw string, failed bool) []model.LogSection {
+	var sections []model.LogSection
+	cur := &model.LogSection{}
+
+	flush := func() {
+		if cur.Name != "" || len(cur.Lines) > 0 {
+			sections = append(sections, *cur)
+		}
+	}
+
+	for _, line := range strings.Split(raw, "\n") {
+		hadMarker := false
+		for {
+			loc := sectionMarker.FindStringSubmatchIndex(line)
+			if loc == nil {
+				break
+			}
+			if before := cleanLogLine(line[:loc[0]]); before != "" {
+				cur.Lines = append(cur.Lines, before)
+			}
+			kind, name := line[loc[2]:loc[3]], line[loc[4]:loc[5]]
+			line = line[loc[1]:]
+			hadMarker = true
+			flush()
+			if kind == "start" {
+				cur = &model.LogSection{Name: name}
+				// The header text follows the start marker on the same line.
+				if next := sectionMarker.FindStringIndex(line); next != nil {
+					cur.Header = cleanLogLine(line[:next[0]])
+					line = line[next[0]:]
+				} else {
+					cur.Header = cleanLogLine(line)
+					line = ""
+				}
+			} else {
+				cur = &model.LogSection{}
+			}
+		}
+		if line != "" || !hadMarker {
+			cur.Lines = append(cur.Lines, cleanLogLine(line))
+		}
+	}
+	flush()
+
+	// Drop trailing blank lines left by the final newline.
+	for i := range sections {
+		lines := sections[i].Lines
+		for len(lines) > 0 && lines[len(lines)-1] == "" {
+			lines = lines[:len(lines)-1]
+		}
+		sections[i].Lines = lines
+	}
+
+	if failed {
+		markFailing(sections)
+	}
+	return sections
+}
+
+func markFailing(sections []model.LogSection) {
+	for i := len(sections) - 1; i >= 0; i-- {
+		if sections[i].Name != "" && !runnerSections[sections[i].Name] {
+			sections[i].Failing = true
+			return
+		}
+	}
+	for i := len(sections) - 1; i >= 0; i-- {
+		if sections[i].Name != "" {
+			sections[i].Failing = true
+			return
+		}
+	}
+}
+
+// cleanLogLine strips ANSI escapes and keeps only the text after the last
+// carriage return, which is what a terminal would have shown.
+func cleanLogLine(s string) string {
+	s = ansiEscape.ReplaceAllString(s, "")
+	if i := strings.LastIndex(strings.TrimRight(s, "\r"), "\r"); i >= 0 {
+		s = s[i+1:]
+	}
+	return strings.TrimRight(s, "\r")
+}
@@ -3,6 +3,7 @@ package gitlab
 import (
 	"context"
 	"encoding/json"
+	"fmt"
 	"os/exec"
 	"time"
 
@@ -15,8 +16,16 @@ type glabMR struct {
 	Title  string `json:"title"`
 	State  string `json:"state"`
 	WebURL string `json:"web_url"`
+	// branch the MR merges from
+	SourceBranch string `json:"source_branch"`
+	// head commit of the source branch
+	SHA    string `json:"sha"`
+	Author struct {
+		Username string `json:"username"`
+	} `json:"author"`
 	// glab mr list includes the latest pipeline for the branch
 	Pipeline *struct {
+		ID     int    `json:"id"`
 		Status string `json:"status"`
 	} `json:"pipeline"`
 	// set to false when there are open blocking discussion threads
@@ -58,18 +67,50 @@ func FetchMR(branch string) (*model.MR, error) {
 		return nil, nil
 	}
 
-	mr := &model.MR{
-		IID:    found.IID,
-		Title:  found.Title,
-		WebURL: found.WebURL,
-		State:  found.State,
+	return found.toModel(), nil
+}
+
+// ListOpenMRs returns the project's open merge requests, newest first.
+func ListOpenMRs() ([]model.MR, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 15*time.Second)
+	defer cancel()
+
+	out, err := exec.CommandContext(ctx,
+		"glab", "mr", "list",
+		"--per-page", "100",
+		"-F", "json",
+	).Output()
+	if err != nil {
+		return nil, fmt.Errorf("glab mr list: %w", err)
 	}
-	if found.Pipeline != nil {
-		mr.PipelineStatus = found.Pipeline.Status
+
+	var mrs []glabMR
+	if err := json.Unmarshal(out, &mrs); err != nil {
+		return nil, fmt.Errorf("decode MRs: %w", err)
 	}
-	if found.BlockingDiscussionsResolved != nil {
-		mr.HasUnresolved = !*found.BlockingDiscussionsResolved
+	result := make([]model.MR, len(mrs))
+	for i := range mrs {
+		result[i] = *mrs[i].toModel()
 	}
+	return result, nil
+}
 
-	return mr, nil
+func (g *glabMR) toModel() *model.MR {
+	mr := &model.MR{
+		IID:          g.IID,
+		Title:        g.Title,
+		WebURL:       g.WebURL,
+		SourceBranch: g.SourceBranch,
+		Author:       g.Author.Username,
+		State:        g.State,
+		SHA:          g.SHA,
+	}
+	if g.Pipeline != nil {
+		mr.PipelineID = g.Pipeline.ID
+		mr.PipelineStatus = g.Pipeline.Status
+	}
+	if g.BlockingDiscussionsResolved != nil {
+		mr.HasUnresolved = !*g.BlockingDiscussionsResolved
+	}
+	return mr
 }
@@ -0,0 +1,159 @@
+package gitlab
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"os/exec"
+	"sort"
+	"strings"
+	"time"
+
+	"deckard/internal/model"
+)
+
+// glabPipeline mirrors the fields we care about from the pipelines API.
+type glabPipeline struct {
+	ID     int    `json:"id"`
+	Status string `json:"status"`
+	Ref    string `json:"ref"`
+	WebURL string `json:"web_url"`
+}
+
+// glabJob mirrors the fields we care about from the pipeline jobs API.
+type glabJob struct {
+	ID           int     `json:"id"`
+	Name         string  `json:"name"`
+	Stage        string  `json:"stage"`
+	Status       string  `json:"status"`
+	Duration     float64 `json:"duration"`
+	AllowFailure bool    `json:"allow_failure"`
+	WebURL       string  `json:"web_url"`
+}
+
+// api runs `glab api` against the current project and returns the raw body.
+// glab expands the :id placeholder to the project of the working directory.
+func api(method, endpoint string) ([]byte, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 15*time.Second)
+	defer cancel()
+
+	args := []string{"api", endpoint}
+	if method != "" && method != "GET" {
+		args = append(args, "-X", method)
+	}
+	out, err := exec.CommandContext(ctx, "glab", args...).Output()
+	if err != nil {
+		if ee, ok := err.(*exec.ExitError); ok && len(ee.Stderr) > 0 {
+			return nil, fmt.Errorf("glab api %s: %s", endpoint, strings.TrimSpace(string(ee.Stderr)))
+		}
+		return nil, fmt.Errorf("glab api %s: %w", endpoint, err)
+	}
+	return out, nil
+}
+
+// FetchPipeline returns the pipeline with the given ID, or the latest pipeline
+// for branch when id is 0, together with its jobs.
+func FetchPipeline(branch string, id int) (*model.Pipeline, error) {
+	var p glabPipeline
+	if id == 0 {
+		out, err := api("GET", "projects/:id/pipelines?per_page=1&ref="+url.QueryEscape(branch))
+		if err != nil {
+			return nil, err
+		}
+		var ps []glabPipeline
+		if err := json.Unmarshal(out, &ps); err != nil {
+			return nil, fmt.Errorf("decode pipelines: %w", err)
+		}
+		if len(ps) == 0 {
+			return nil, fmt.Errorf("no pipeline found for %s", branch)
+		}
+		p = ps[0]
+	} else {
+		out, err := api("GET", fmt.Sprintf("projects/:id/pipelines/%d", id))
+		if err != nil {
+			return nil, err
+		}
+		if err := json.Unmarshal(out, &p); err != nil {
+			return nil, fmt.Errorf("decode pipeline: %w", err)
+		}
+	}
+
+	out, err := api("GET", fmt.Sprintf("projects/:id/pipelines/%d/jobs?per_page=100", p.ID))
+	if err != nil {
+		return nil, err
+	}
+	var jobs []glabJob
+	if err := json.Unmarshal(out, &jobs); err != nil {
+		return nil, fmt.Errorf("decode jobs: %w", err)
+	}
+
+	pipeline := &model.Pipeline{
+		ID:     p.ID,
+		Status: p.Status,
+		Ref:    p.Ref,
+		WebURL: p.WebURL,
+	}
+	// The API returns jobs newest first; keep stage order as first seen from
+	// the oldest job so stages read top-to-bottom in execution order.
+	stageOrder := map[string]int{}
+	for i := len(jobs) - 1; i >= 0; i-- {
+		if _, ok := stageOrder[jobs[i].Stage]; !ok {
+			stageOrder[jobs[i].Stage] = len(stageOrder)
+		}
+	}
+	for _, j := range jobs {
+		pipeline.Jobs = append(pipeline.Jobs, model.Job{
+			ID:           j.ID,
+			Name:         j.Name,
+			Stage:        j.Stage,
+			Status:       j.Status,
+			Duration:     time.Duration(j.Duration * float64(time.Second)),
+			AllowFailure: j.AllowFailure,
+			WebURL:       j.WebURL,
+		})
+	}
+	sort.SliceStable(pipeline.Jobs, func(a, b int) bool {
+		ja, jb := pipeline.Jobs[a], pipeline.Jobs[b]
+		if stageOrder[ja.Stage] != stageOrder[jb.Stage] {
+			return stageOrder[ja.Stage] < stageOrder[jb.Stage]
+		}
+		return ja.Name < jb.Name
+	})
+
+	return pipeline, nil
+}
+
+// FetchJobLog returns the raw trace of a job, including ANSI escapes and
+// section markers. Use ParseJobLog to turn it into displayable sections.
+func FetchJobLog(jobID int) (string, error) {
+	out, err := api("GET", fmt.Sprintf("projects/:id/jobs/%d/trace", jobID))
+	if err != nil {
+		return "", err
+	}
+	return string(out), nil
+}
+
+// RetryJob re-runs a single job.
+func RetryJob(jobID int) error {
+	_, err := api("POST", fmt.Sprintf("projects/:id/jobs/%d/retry", jobID))
+	return err
+}
+
+// CancelJob cancels a running or pending job.
+func CancelJob(jobID int) error {
+	_, err := api("POST", fmt.Sprintf("projects/:id/jobs/%d/cancel", jobID))
+	return err
+}
+
+// RetryPipeline re-runs every failed or canceled job in a pipeline.
+func RetryPipeline(pipelineID int) error {
+	_, err := api("POST", fmt.Sprintf("projects/:id/pipelines/%d/retry", pipelineID))
+	return err
+}
+
+// CancelPipeline cancels all running and pending jobs in a pipeline.
+func CancelPipeline(pipelineID int) error {
+	_, err := api("POST", fmt.Sprintf("projects/:id/pipelines/%d/cancel", pipelineID))
+	return err
+}
@@ -0,0 +1,28 @@
+package model
+
+import (
+	"strings"
+	"time"
+)
+
+// Meta is what Deckard remembers about a session beyond git and tmux. It is
+// kept in the store, keyed by worktree path.
+type Meta struct {
+	Notes     string    `json:"notes,omitempty"`
+	Tags      []string  `json:"tags,omitempty"`
+	Priority  int       `json:"priority,omitempty"` // 1 (high) to 3 (low); 0 if unset
+	Pinned    bool      `json:"pinned,omitempty"`
+	CreatedAt time.Time `json:"created_at"`
+	Agent     string    `json:"agent,omitempty"`  // agent profile the session was started with
+	Prompt    string    `json:"prompt,omitempty"` // task prompt the session was started with
+}
+
+// HasTag reports whether m is tagged tag, ignoring case.
+func (m Meta) HasTag(tag string) bool {
+	for _, t := range m.Tags {
+		if strings.EqualFold(t, tag) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,32 @@
+package model
+
+import "time"
+
+// Pipeline holds a GitLab pipeline and its jobs, fetched on demand via glab.
+type Pipeline struct {
+	ID     int
+	Status string
+	Ref    string
+	WebURL string
+	Jobs   []Job // ordered by stage, then name
+}
+
+// Job is a single CI job within a pipeline.
+type Job struct {
+	ID           int
+	Name         string
+	Stage        string
+	Status       string
+	Duration     time.Duration
+	AllowFailure bool
+	WebURL       string
+}
+
+// LogSection is a collapsible section of a job log, delimited by GitLab's
+// section_start/section_end markers. Text outside any section has no Name.
+type LogSection struct {
+	Name    string
+	Header  string
+	Lines   []string
+	Failing bool // true for the section the job most likely failed in
+}
@@ -1,21 +1,79 @@
 package model
 
+import "time"
+
 // MR holds GitLab merge request metadata fetched via glab.
 type MR struct {
 	IID            int
 	Title          string
 	WebURL         string
+	SourceBranch   string
+	Author         string
 	State          string // "opened", "merged", "closed"
+	PipelineID     int    // 0 if the MR has no pipeline
 	PipelineStatus string // "success", "failed", "running", "pending", "canceled", etc.
 	HasUnresolved  bool   // true if blocking discussions are unresolved
+	SHA            string // head commit of the source branch the MR last saw
 }
 
 // Session represents a git worktree and its associated work context.
 type Session struct {
-	Path        string
-	Branch      string
-	Slug        string // normalised task name, e.g. "JIRA-182-payment-retries"
-	NeedsInput  bool
-	TmuxRunning bool // whether a live tmux session exists for this worktree
-	MR          *MR  // nil if no MR found or glab unavailable
+	Path         string
+	Branch       string
+	Slug         string    // normalised task name, e.g. "JIRA-182-payment-retries"
+	Base         string    // ref the branch was started from; empty if unknown
+	Ahead        int       // commits on the branch not on Base
+	Behind       int       // commits on Base not on the branch
+	Uncommitted  int       // modified, staged or untracked files
+	LastActive   time.Time // latest of the HEAD commit and the agent's last conversation write
+	Variant      string    // variant group the branch competes in; empty if none
+	Parent       string    // branch this one was forked from; empty if none
+	NeedsInput   bool
+	TmuxRunning  bool     // whether a live tmux session exists for this worktree
+	Windows      []Window // windows of the tmux session, agent first; nil if none
+	AgentExited  bool     // the agent has exited since it was last started
+	ExitStatus   int      // the agent's exit status, if it exited
+	Conversation string   // the running agent's latest conversation; empty if none
+	Ports        []Port   // named ports of the worktree's reserved block
+	MR           *MR      // nil if no MR found or glab unavailable
+	Stage        Stage
+	StageManual  bool // Stage was set by hand rather than derived
+	Meta         Meta // from the store; zero if nothing is recorded
+}
+
+// Crashed reports whether the agent exited with an error.
+func (s Session) Crashed() bool { return s.AgentExited && s.ExitStatus != 0 }
+
+// Window is a window of a session's tmux layout, as seen through its first
+// pane.
+type Window struct {
+	Index      int
+	Name       string
+	Command    string // process in the foreground of the pane
+	Dead       bool   // the pane's command has exited
+	ExitStatus int    // exit status of a dead pane
+}
+
+// Port is a port of a worktree's reserved block, named after the
+// environment variable it is exported as.
+type Port struct {
+	Name      string
+	Number    int
+	Listening bool
+}
+
+// Thread is an unresolved MR discussion thread.
+type Thread struct {
+	ID     string
+	File   string // empty for general (non-diff) discussions
+	Line   int
+	Author string
+	Body   string
+	Notes  []Note // replies after the first note
+}
+
+// Note is a single reply within a Thread.
+type Note struct {
+	Author string
+	Body   string
 }
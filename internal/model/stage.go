@@ -0,0 +1,46 @@
+package model
+
+// Stage is where a session is in its lifecycle.
+type Stage string
+
+const (
+	StagePlanning   Stage = "planning"       // no commits yet
+	StageWorking    Stage = "working"        // commits, no open MR
+	StageNeedsInput Stage = "needs-input"    // the agent or a reviewer is waiting on a human
+	StageInReview   Stage = "in-review"      // open MR, pipeline not yet green
+	StageCIFailing  Stage = "ci-failing"     // open MR with a failed pipeline
+	StageReady      Stage = "ready-to-merge" // open MR, pipeline passed, threads resolved
+	StageMerged     Stage = "merged"
+)
+
+// Stages lists every stage in lifecycle order.
+var Stages = []Stage{
+	StagePlanning,
+	StageWorking,
+	StageNeedsInput,
+	StageInReview,
+	StageCIFailing,
+	StageReady,
+	StageMerged,
+}
+
+// DeriveStage works out the stage of s from its tmux, git and MR state.
+func DeriveStage(s Session) Stage {
+	open := s.MR != nil && s.MR.State == "opened"
+	switch {
+	case s.MR != nil && s.MR.State == "merged":
+		return StageMerged
+	case open && s.MR.PipelineStatus == "failed":
+		return StageCIFailing
+	case s.NeedsInput:
+		return StageNeedsInput
+	case open && s.MR.PipelineStatus == "success":
+		return StageReady
+	case open:
+		return StageInReview
+	case s.Ahead == 0:
+		return StagePlanning
+	default:
+		return StageWorking
+	}
+}
@@ -0,0 +1,28 @@
+package model
+
+import "testing"
+
+func TestDeriveStage(t *testing.T) {
+	mr := func(state, pipeline string) *MR { return &MR{State: state, PipelineStatus: pipeline} }
+
+	tests := []struct {
+		name string
+		s    Session
+		want Stage
+	}{
+		{"fresh worktree", Session{}, StagePlanning},
+		{"commits", Session{Ahead: 3}, StageWorking},
+		{"agent waiting", Session{Ahead: 3, NeedsInput: true}, StageNeedsInput},
+		{"open MR", Session{Ahead: 3, MR: mr("opened", "running")}, StageInReview},
+		{"green MR", Session{MR: mr("opened", "success")}, StageReady},
+		{"green MR, agent waiting", Session{NeedsInput: true, MR: mr("opened", "success")}, StageNeedsInput},
+		{"red MR", Session{NeedsInput: true, MR: mr("opened", "failed")}, StageCIFailing},
+		{"merged", Session{NeedsInput: true, MR: mr("merged", "failed")}, StageMerged},
+		{"closed MR", Session{Ahead: 1, MR: mr("closed", "failed")}, StageWorking},
+	}
+	for _, tt := range tests {
+		if got := DeriveStage(tt.s); got != tt.want {
+			t.Errorf("%s: got %s, want %s", tt.name, got, tt.want)
+		}
+	}
+}
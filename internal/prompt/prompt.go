@@ -0,0 +1,121 @@
+package prompt
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"deckard/internal/model"
+)
+
+// JobLog is the trimmed log of a single failing CI job.
+type JobLog struct {
+	Job   model.Job
+	Lines []string
+}
+
+// CIFix builds the prompt asking the agent to fix a failed pipeline.
+func CIFix(branch string, p *model.Pipeline, logs []JobLog) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "CI failed on branch %s (pipeline #%d", branch, p.ID)
+	if p.WebURL != "" {
+		fmt.Fprintf(&b, ", %s", p.WebURL)
+	}
+	b.WriteString(").\n\n")
+
+	for _, l := range logs {
+		fmt.Fprintf(&b, "## Job %q (stage %s)\n\n", l.Job.Name, l.Job.Stage)
+		b.WriteString("```\n")
+		for _, line := range l.Lines {
+			b.WriteString(line + "\n")
+		}
+		b.WriteString("```\n\n")
+	}
+
+	b.WriteString("Please find the cause of the failure above, fix it, and run the relevant " +
+		"checks locally to confirm. Commit the fix when you are done.")
+	return b.String()
+}
+
+// TailLines returns the last n lines of the failing section of a parsed log,
+// falling back to the tail of the whole log if no section is marked failing.
+func TailLines(sections []model.LogSection, n int) []string {
+	var lines []string
+	for _, s := range sections {
+		if s.Failing {
+			if s.Header != "" {
+				lines = append(lines, s.Header)
+			}
+			lines = append(lines, s.Lines...)
+		}
+	}
+	if len(lines) == 0 {
+		for _, s := range sections {
+			if s.Header != "" {
+				lines = append(lines, s.Header)
+			}
+			lines = append(lines, s.Lines...)
+		}
+	}
+	if n > 0 && len(lines) > n {
+		lines = lines[len(lines)-n:]
+	}
+	return lines
+}
+
+// Review builds the prompt asking the agent to address unresolved MR threads.
+func Review(branch string, mr *model.MR, threads []model.Thread) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "Reviewers left %d unresolved thread(s) on MR !%d (%s) for branch %s.\n\n",
+		len(threads), mr.IID, mr.Title, branch)
+
+	for i, t := range threads {
+		fmt.Fprintf(&b, "## Thread %d", i+1)
+		if t.File != "" {
+			fmt.Fprintf(&b, " — %s:%d", t.File, t.Line)
+		}
+		b.WriteString("\n\n")
+		fmt.Fprintf(&b, "@%s: %s\n", t.Author, t.Body)
+		for _, n := range t.Notes {
+			fmt.Fprintf(&b, "@%s: %s\n", n.Author, n.Body)
+		}
+		b.WriteString("\n")
+	}
+
+	b.WriteString("Please address each thread: change the code where the feedback is valid, " +
+		"and explain briefly where you disagree. Commit the changes when you are done, " +
+		"and finish with a list of the thread numbers you addressed.")
+	return b.String()
+}
+
+// ticketPattern matches an issue key such as JIRA-182. Keys are uppercase, so
+// lowercase words like the retry-2 in add-retry-2 aren't taken for one.
+var ticketPattern = regexp.MustCompile(`\b([A-Z][A-Z0-9]+-\d+)`)
+
+// Vars returns the template variables available to task prompts. base is the
+// ref the branch actually starts from, never empty (see git.ResolveBase).
+func Vars(branch, base string) map[string]string {
+	vars := map[string]string{
+		"branch": branch,
+		"base":   base,
+		"ticket": "",
+	}
+	if m := ticketPattern.FindStringSubmatch(branch); m != nil {
+		vars["ticket"] = m[1]
+	}
+	return vars
+}
+
+// Expand replaces {{name}} placeholders in tmpl with vars. Unknown
+// placeholders are left untouched so typos are visible to the agent and user.
+func Expand(tmpl string, vars map[string]string) string {
+	return placeholder.ReplaceAllStringFunc(tmpl, func(m string) string {
+		name := strings.TrimSpace(m[2 : len(m)-2])
+		if v, ok := vars[name]; ok {
+			return v
+		}
+		return m
+	})
+}
+
+var placeholder = regexp.MustCompile(`\{\{\s*[a-z_]+\s*\}\}`)
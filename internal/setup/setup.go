@@ -0,0 +1,175 @@
+package setup
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"sync"
+	"syscall"
+	"time"
+
+	"deckard/internal/config"
+)
+
+// Run performs the configured setup steps in the worktree at path: copies,
+// then symlinks, then commands. Each step's progress, including command
+// output, is reported line by line through progress. Copy and symlink
+// failures don't stop later steps; the first failing command stops the
+// remaining commands. All failures are joined into the returned error.
+// Commands see env on top of Deckard's own environment.
+func Run(repoRoot, path string, cfg config.Setup, env []string, progress func(string)) error {
+	var errs []error
+
+	for _, rel := range cfg.Copy {
+		progress("copy " + rel)
+		if err := copyPath(filepath.Join(repoRoot, rel), filepath.Join(path, rel)); err != nil {
+			progress("  ✕ " + err.Error())
+			errs = append(errs, fmt.Errorf("copy %s: %w", rel, err))
+		}
+	}
+
+	for _, rel := range cfg.Symlink {
+		progress("link " + rel)
+		if err := linkPath(filepath.Join(repoRoot, rel), filepath.Join(path, rel)); err != nil {
+			progress("  ✕ " + err.Error())
+			errs = append(errs, fmt.Errorf("symlink %s: %w", rel, err))
+		}
+	}
+
+	for _, c := range cfg.Run {
+		progress("$ " + c.Cmd)
+		if err := RunCommand(path, c, env, progress); err != nil {
+			progress("  ✕ " + err.Error())
+			errs = append(errs, fmt.Errorf("%s: %w", c.Cmd, err))
+			break
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+// killGrace is how long RunCommand waits for output to drain after killing a
+// timed-out command before giving up on it.
+const killGrace = 2 * time.Second
+
+// RunCommand runs c with sh -c in dir, with env added to the environment,
+// reporting each line of its output through progress. On timeout the whole
+// process group is killed, not just the shell, so servers and watchers the
+// command started don't outlive it.
+func RunCommand(dir string, c config.Command, env []string, progress func(string)) error {
+	ctx, cancel := context.WithTimeout(context.Background(), c.Timeout())
+	defer cancel()
+
+	cmd := exec.CommandContext(ctx, "sh", "-c", c.Cmd)
+	cmd.Dir = dir
+	cmd.Env = append(os.Environ(), env...)
+	cmd.SysProcAttr = &syscall.SysProcAttr{Setpgid: true}
+	cmd.Cancel = func() error {
+		return syscall.Kill(-cmd.Process.Pid, syscall.SIGKILL)
+	}
+	cmd.WaitDelay = killGrace
+
+	pr, pw := io.Pipe()
+	cmd.Stdout = pw
+	cmd.Stderr = pw
+
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		sc := bufio.NewScanner(pr)
+		for sc.Scan() {
+			progress("  " + sc.Text())
+		}
+		io.Copy(io.Discard, pr)
+	}()
+
+	err := cmd.Run()
+	pw.Close()
+	wg.Wait()
+
+	if ctx.Err() == context.DeadlineExceeded {
+		return fmt.Errorf("timed out after %s", c.Timeout())
+	}
+	return err
+}
+
+// copyPath copies a file or directory tree, preserving file modes. An
+// existing destination is left alone, since the checkout may already have it.
+func copyPath(src, dst string) error {
+	if _, err := os.Lstat(dst); err == nil {
+		return nil
+	}
+	return filepath.WalkDir(src, func(p string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		rel, _ := filepath.Rel(src, p)
+		target := filepath.Join(dst, rel)
+		info, err := d.Info()
+		if err != nil {
+			return err
+		}
+		switch {
+		case d.IsDir():
+			return os.MkdirAll(target, info.Mode().Perm())
+		case d.Type()&fs.ModeSymlink != 0:
+			link, err := os.Readlink(p)
+			if err != nil {
+				return err
+			}
+			if err := os.MkdirAll(filepath.Dir(target), 0755); err != nil {
+				return err
+			}
+			return os.Symlink(link, target)
+		default:
+			return copyFile(p, target, info.Mode().Perm())
+		}
+	})
+}
+
+func copyFile(src, dst string, mode fs.FileMode) error {
+	if err := os.MkdirAll(filepath.Dir(dst), 0755); err != nil {
+		return err
+	}
+	in, err := os.Open(src)
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+	out, err := os.OpenFile(dst, os.O_CREATE|os.O_WRONLY|os.O_EXCL, mode)
+	if err != nil {
+		return err
+	}
+	if _, err := io.Copy(out, in); err != nil {
+		out.Close()
+		return err
+	}
+	return out.Close()
+}
+
+// linkPath symlinks dst to the absolute src. An existing destination is left
+// alone.
+func linkPath(src, dst string) error {
+	if _, err := os.Lstat(dst); err == nil {
+		return nil
+	}
+	if _, err := os.Stat(src); err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(dst), 0755); err != nil {
+		return err
+	}
+	abs, err := filepath.Abs(src)
+	if err != nil {
+		return err
+	}
+	return os.Symlink(abs, strings.TrimSuffix(dst, "/"))
+}
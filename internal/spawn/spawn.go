@@ -0,0 +1,223 @@
+package spawn
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"gopkg.in/yaml.v3"
+
+	"deckard/internal/config"
+	"deckard/internal/git"
+	"deckard/internal/model"
+	"deckard/internal/prompt"
+	"deckard/internal/setup"
+	"deckard/internal/store"
+	"deckard/internal/tmux"
+)
+
+// Task is one entry of a task file: a session to create.
+type Task struct {
+	Branch string `yaml:"branch"`
+	Base   string `yaml:"base"`   // optional; defaults to origin/<default branch>
+	Agent  string `yaml:"agent"`  // optional agent profile; defaults to config's default_agent
+	Prompt string `yaml:"prompt"` // first prompt; may use {{branch}}, {{ticket}} and {{base}}
+
+	// Variants > 1 turns the task into that many competing sessions,
+	// <branch>-v1..vN, all with the same base and prompt.
+	Variants int `yaml:"variants"`
+
+	// Variant is the variant group the branch joins; set by ExpandVariants.
+	Variant string `yaml:"-"`
+}
+
+// Result reports what happened to a Task.
+type Result struct {
+	Task   Task
+	Slug   string
+	Path   string // set once the worktree exists, even if a later step failed
+	Agent  string // agent profile used
+	Prompt string // first prompt, expanded
+	Stale  error  // why the base could not be fetched; the last-fetched one was used
+	Err    error
+}
+
+// Started reports whether the task's session is running.
+func (r Result) Started() bool { return r.Err == nil }
+
+// Meta returns what the store should remember about the task's session.
+func (r Result) Meta() model.Meta {
+	return model.Meta{CreatedAt: time.Now(), Agent: r.Agent, Prompt: r.Prompt}
+}
+
+// LoadTasks reads a YAML task file. The file is either a list of tasks or a
+// mapping with a "tasks" list.
+func LoadTasks(path string) ([]Task, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("read tasks: %w", err)
+	}
+
+	// Decode the form the document is in, so errors point into it.
+	var tasks []Task
+	var root yaml.Node
+	err = yaml.Unmarshal(data, &root)
+	switch {
+	case err != nil:
+	case len(root.Content) == 0:
+	case root.Content[0].Kind == yaml.MappingNode:
+		var doc struct {
+			Tasks []Task `yaml:"tasks"`
+		}
+		err = root.Decode(&doc)
+		tasks = doc.Tasks
+	default:
+		err = root.Decode(&tasks)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("parse %s: %w", path, err)
+	}
+	tasks = ExpandVariants(tasks)
+
+	var errs []error
+	seen := map[string]bool{}
+	for i, t := range tasks {
+		switch {
+		case strings.TrimSpace(t.Branch) == "":
+			errs = append(errs, fmt.Errorf("task %d: branch is required", i+1))
+		case seen[t.Branch]:
+			errs = append(errs, fmt.Errorf("task %d: duplicate branch %q", i+1, t.Branch))
+		}
+		seen[t.Branch] = true
+	}
+	if len(tasks) == 0 {
+		errs = append(errs, fmt.Errorf("%s has no tasks", path))
+	}
+	return tasks, errors.Join(errs...)
+}
+
+// ExpandVariants replaces every task asking for several variants with one
+// task per variant, grouped under the original branch name.
+func ExpandVariants(tasks []Task) []Task {
+	var out []Task
+	for _, t := range tasks {
+		if t.Variants <= 1 {
+			out = append(out, t)
+			continue
+		}
+		for i := 1; i <= t.Variants; i++ {
+			v := t
+			v.Branch = fmt.Sprintf("%s-v%d", t.Branch, i)
+			v.Variants = 0
+			v.Variant = t.Branch
+			out = append(out, v)
+		}
+	}
+	return out
+}
+
+// Run creates a worktree, runs setup and starts a detached session for every
+// task, at most concurrency at a time. done is called as each task finishes,
+// from the task's goroutine. Results are returned in task order.
+func Run(repoRoot string, cfg config.Config, tasks []Task, concurrency int, done func(Result)) []Result {
+	if concurrency < 1 {
+		concurrency = 1
+	}
+	results := make([]Result, len(tasks))
+	sem := make(chan struct{}, concurrency)
+	var wg sync.WaitGroup
+	for i, t := range tasks {
+		wg.Add(1)
+		go func(i int, t Task) {
+			defer wg.Done()
+			sem <- struct{}{}
+			defer func() { <-sem }()
+			results[i] = One(repoRoot, cfg, t)
+			if done != nil {
+				done(results[i])
+			}
+		}(i, t)
+	}
+	wg.Wait()
+	return results
+}
+
+var gitMu sync.Mutex
+
+// reservePorts reserves a port block for the worktree at path and returns its
+// variables. The store is reopened and saved straight away, since several
+// tasks reserve blocks in turn; callers hold gitMu so they don't race.
+func reservePorts(repoRoot string, cfg config.Ports, path string) ([]string, error) {
+	st, err := store.Open(repoRoot)
+	if err != nil {
+		return nil, err
+	}
+	start, err := st.AllocPorts(path, cfg.From, cfg.To, cfg.PerWorktree)
+	if err != nil {
+		return nil, err
+	}
+	if err := st.Save(); err != nil {
+		return nil, err
+	}
+	return cfg.Environ(start), nil
+}
+
+// One creates and starts the session for a single task.
+func One(repoRoot string, cfg config.Config, t Task) Result {
+	r := Result{
+		Task:   t,
+		Slug:   git.BranchToSlug(t.Branch),
+		Agent:  t.Agent,
+		Prompt: prompt.Expand(t.Prompt, prompt.Vars(t.Branch, git.ResolveBase(repoRoot, t.Base))),
+	}
+	if r.Agent == "" {
+		r.Agent = cfg.DefaultAgent
+	}
+
+	// Like the TUI, fall back to the built-in agent when default_agent names
+	// no profile; a profile the task names itself has to exist.
+	command := cfg.DefaultAgentCommand()
+	var err error
+	if t.Agent != "" {
+		if command, err = cfg.AgentCommand(t.Agent); err != nil {
+			r.Err = err
+			return r
+		}
+	}
+
+	// Worktree creation writes the shared .git config and refs, so it is
+	// serialised; setup and agent start-up run in parallel.
+	gitMu.Lock()
+	r.Stale = git.FetchBase(repoRoot, t.Base)
+	r.Path, err = git.CreateWorktree(repoRoot, t.Branch, t.Base)
+	if err == nil && t.Variant != "" {
+		err = git.SetBranchVariant(repoRoot, t.Branch, t.Variant)
+	}
+	gitMu.Unlock()
+	if err != nil {
+		r.Err = fmt.Errorf("create worktree: %w", err)
+		return r
+	}
+
+	gitMu.Lock()
+	env, err := reservePorts(repoRoot, cfg.Ports, r.Path)
+	gitMu.Unlock()
+	if err != nil {
+		r.Err = fmt.Errorf("reserve ports (worktree kept): %w", err)
+		return r
+	}
+
+	if err := setup.Run(repoRoot, r.Path, cfg.Setup, env, func(string) {}); err != nil {
+		r.Err = fmt.Errorf("setup (worktree kept): %w", err)
+		return r
+	}
+
+	opts := tmux.Options{Command: command, Prompt: r.Prompt, Tmux: cfg.Tmux, Layout: cfg.Layout, Env: env}
+	if err := tmux.EnsureSession(r.Slug, r.Path, opts); err != nil {
+		r.Err = fmt.Errorf("start session: %w", err)
+	}
+	return r
+}
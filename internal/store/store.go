@@ -0,0 +1,216 @@
+package store
+
+import (
+	"crypto/sha1"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"deckard/internal/model"
+)
+
+// Store is Deckard's persistent per-repo state, kept as a JSON file under the
+// user data dir so it survives restarts without touching the repo itself.
+type Store struct {
+	path string
+
+	Repo    string           `json:"repo"`
+	CIFixes map[string]CIFix `json:"ci_fixes"` // keyed by branch
+	Trash   []TrashEntry     `json:"trash"`    // oldest first
+
+	// Stages holds manual lifecycle overrides, keyed by worktree path.
+	Stages map[string]StageOverride `json:"stages"`
+
+	// Meta holds notes, tags and the like for live worktrees, keyed by
+	// worktree path. Entries move into the trash with their worktree.
+	Meta map[string]model.Meta `json:"meta"`
+
+	List ListPrefs `json:"list"`
+
+	// Ports holds each worktree's reserved block, keyed by worktree path.
+	Ports map[string]PortBlock `json:"ports"`
+
+	// Live records the worktrees whose agent was running at the last
+	// refresh, keyed by worktree path, so they can be restored after the
+	// tmux server is lost.
+	Live map[string]LiveSession `json:"live"`
+}
+
+// LiveSession is how to bring back a session that was running.
+type LiveSession struct {
+	Slug         string `json:"slug"`
+	Agent        string `json:"agent,omitempty"`        // agent profile; empty for the default
+	Conversation string `json:"conversation,omitempty"` // claude conversation to resume
+}
+
+// PortBlock is a range of ports reserved for one worktree. The size is kept
+// with the start because pools differ between repos and can be reconfigured.
+type PortBlock struct {
+	Start int `json:"start"`
+	Size  int `json:"size"`
+}
+
+// overlaps reports whether b shares a port with the size ports from start.
+func (b PortBlock) overlaps(start, size int) bool {
+	return start < b.Start+b.Size && b.Start < start+size
+}
+
+// ListPrefs is how the session list is ordered and grouped.
+type ListPrefs struct {
+	Sort      string   `json:"sort,omitempty"`      // empty for the default order
+	Group     string   `json:"group,omitempty"`     // empty for no grouping
+	Collapsed []string `json:"collapsed,omitempty"` // "<group mode>/<group>" of collapsed groups
+}
+
+// CIFix records automatic "fix CI" attempts for a branch.
+type CIFix struct {
+	Attempts     int       `json:"attempts"`
+	LastPipeline int       `json:"last_pipeline"` // pipeline the last prompt was sent for
+	LastAt       time.Time `json:"last_at"`
+}
+
+// StageOverride is a lifecycle stage set by hand. It holds until the derived
+// stage moves on from Derived, so an override never outlives the situation
+// it was made for.
+type StageOverride struct {
+	Stage   string    `json:"stage"`
+	Derived string    `json:"derived"` // derived stage when the override was made
+	SetAt   time.Time `json:"set_at"`
+}
+
+// TrashEntry describes an archived worktree. Its commits are kept alive by
+// refs under git.TrashRefPrefix + ID.
+type TrashEntry struct {
+	ID         string      `json:"id"`
+	Slug       string      `json:"slug"`
+	Branch     string      `json:"branch"`
+	Path       string      `json:"path"`
+	Tip        string      `json:"tip"`                // branch tip commit
+	Snapshot   string      `json:"snapshot,omitempty"` // uncommitted changes, if any
+	MRIID      int         `json:"mr_iid,omitempty"`
+	ArchivedAt time.Time   `json:"archived_at"`
+	Meta       *model.Meta `json:"meta,omitempty"`
+}
+
+// DataDir returns Deckard's data directory, honouring $XDG_DATA_HOME.
+func DataDir() (string, error) {
+	if d := os.Getenv("XDG_DATA_HOME"); d != "" {
+		return filepath.Join(d, "deckard"), nil
+	}
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", fmt.Errorf("home dir: %w", err)
+	}
+	return filepath.Join(home, ".local", "share", "deckard"), nil
+}
+
+// Open loads the store for the repo at repoRoot, returning an empty store if
+// none has been saved yet.
+func Open(repoRoot string) (*Store, error) {
+	dir, err := DataDir()
+	if err != nil {
+		return nil, err
+	}
+	sum := sha1.Sum([]byte(repoRoot))
+	s := &Store{
+		path: filepath.Join(dir, "repos", hex.EncodeToString(sum[:8])+".json"),
+		Repo: repoRoot,
+	}
+
+	data, err := os.ReadFile(s.path)
+	switch {
+	case errors.Is(err, os.ErrNotExist):
+	case err != nil:
+		return nil, fmt.Errorf("read store: %w", err)
+	default:
+		if err := json.Unmarshal(data, s); err != nil {
+			return nil, fmt.Errorf("parse store %s: %w", s.path, err)
+		}
+	}
+	if s.CIFixes == nil {
+		s.CIFixes = map[string]CIFix{}
+	}
+	if s.Stages == nil {
+		s.Stages = map[string]StageOverride{}
+	}
+	if s.Meta == nil {
+		s.Meta = map[string]model.Meta{}
+	}
+	if s.Ports == nil {
+		s.Ports = map[string]PortBlock{}
+	}
+	if s.Live == nil {
+		s.Live = map[string]LiveSession{}
+	}
+	return s, nil
+}
+
+// Remember records meta for a new worktree at path, unless something is
+// already recorded for it.
+func (s *Store) Remember(path string, meta model.Meta) {
+	if _, ok := s.Meta[path]; !ok {
+		s.Meta[path] = meta
+	}
+}
+
+// AllocPorts returns the first port of the block reserved for the worktree at
+// path. A worktree without one gets the lowest free block of size ports in
+// from–to, stepping by size from from; a block is free if it overlaps none
+// reserved here or in other repos' stores. The caller saves the store.
+func (s *Store) AllocPorts(path string, from, to, size int) (int, error) {
+	if b, ok := s.Ports[path]; ok {
+		return b.Start, nil
+	}
+	if size < 1 {
+		return 0, fmt.Errorf("ports.per_worktree must be at least 1")
+	}
+	var taken []PortBlock
+	for _, b := range s.Ports {
+		taken = append(taken, b)
+	}
+	others, _ := filepath.Glob(filepath.Join(filepath.Dir(s.path), "*.json"))
+	for _, p := range others {
+		if p == s.path {
+			continue
+		}
+		var other struct {
+			Ports map[string]PortBlock `json:"ports"`
+		}
+		if data, err := os.ReadFile(p); err == nil && json.Unmarshal(data, &other) == nil {
+			for _, b := range other.Ports {
+				taken = append(taken, b)
+			}
+		}
+	}
+next:
+	for start := from; start+size-1 <= to; start += size {
+		for _, b := range taken {
+			if b.overlaps(start, size) {
+				continue next
+			}
+		}
+		s.Ports[path] = PortBlock{Start: start, Size: size}
+		return start, nil
+	}
+	return 0, fmt.Errorf("no free port block in %d-%d", from, to)
+}
+
+// Save writes the store atomically.
+func (s *Store) Save() error {
+	if err := os.MkdirAll(filepath.Dir(s.path), 0755); err != nil {
+		return fmt.Errorf("mkdir: %w", err)
+	}
+	data, err := json.MarshalIndent(s, "", "  ")
+	if err != nil {
+		return fmt.Errorf("encode store: %w", err)
+	}
+	tmp := s.path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0644); err != nil {
+		return fmt.Errorf("write store: %w", err)
+	}
+	return os.Rename(tmp, s.path)
+}
@@ -0,0 +1,98 @@
+package store
+
+import (
+	"testing"
+)
+
+func openTemp(t *testing.T, repoRoot string) *Store {
+	t.Helper()
+	s, err := Open(repoRoot)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return s
+}
+
+func TestAllocPorts(t *testing.T) {
+	t.Setenv("XDG_DATA_HOME", t.TempDir())
+	s := openTemp(t, "/repo")
+
+	a, err := s.AllocPorts("/repo/a", 4000, 4029, 10)
+	if err != nil || a != 4000 {
+		t.Fatalf("first block = %d, %v; want 4000", a, err)
+	}
+	b, _ := s.AllocPorts("/repo/b", 4000, 4029, 10)
+	if b != 4010 {
+		t.Errorf("second block = %d, want 4010", b)
+	}
+	if again, _ := s.AllocPorts("/repo/a", 4000, 4029, 10); again != a {
+		t.Errorf("a's block moved from %d to %d", a, again)
+	}
+
+	// A released block is reused before the pool grows.
+	delete(s.Ports, "/repo/a")
+	if c, _ := s.AllocPorts("/repo/c", 4000, 4029, 10); c != 4000 {
+		t.Errorf("block after release = %d, want 4000", c)
+	}
+}
+
+func TestAllocPortsAcrossRepos(t *testing.T) {
+	t.Setenv("XDG_DATA_HOME", t.TempDir())
+	other := openTemp(t, "/other")
+	if _, err := other.AllocPorts("/other/a", 4000, 4029, 10); err != nil {
+		t.Fatal(err)
+	}
+	// Unsaved reservations are invisible to other stores.
+	s := openTemp(t, "/repo")
+	if got, _ := s.AllocPorts("/repo/probe", 4000, 4029, 10); got != 4000 {
+		t.Errorf("before save got %d, want 4000", got)
+	}
+	delete(s.Ports, "/repo/probe")
+
+	if err := other.Save(); err != nil {
+		t.Fatal(err)
+	}
+	if got, _ := s.AllocPorts("/repo/a", 4000, 4029, 10); got != 4010 {
+		t.Errorf("got %d, want 4010 past the other repo's block", got)
+	}
+}
+
+func TestAllocPortsExhausted(t *testing.T) {
+	t.Setenv("XDG_DATA_HOME", t.TempDir())
+	s := openTemp(t, "/repo")
+
+	// 4000-4014 holds one whole block of 10; the remainder is never handed out.
+	if _, err := s.AllocPorts("/repo/a", 4000, 4014, 10); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := s.AllocPorts("/repo/b", 4000, 4014, 10); err == nil {
+		t.Error("allocated past the end of the pool")
+	}
+	if _, ok := s.Ports["/repo/b"]; ok {
+		t.Error("failed allocation was recorded")
+	}
+	if _, err := s.AllocPorts("/repo/c", 4000, 4999, 0); err == nil {
+		t.Error("allocated an empty block")
+	}
+}
+
+// Pools can differ between repos, so blocks that start at different ports
+// can still share some.
+func TestAllocPortsOverlappingPools(t *testing.T) {
+	t.Setenv("XDG_DATA_HOME", t.TempDir())
+	other := openTemp(t, "/other")
+	if _, err := other.AllocPorts("/other/a", 4005, 4099, 5); err != nil {
+		t.Fatal(err)
+	}
+	if err := other.Save(); err != nil {
+		t.Fatal(err)
+	}
+
+	s := openTemp(t, "/repo")
+	if got, _ := s.AllocPorts("/repo/a", 4000, 4099, 10); got != 4010 {
+		t.Errorf("got %d, want 4010 clear of 4005-4009", got)
+	}
+	if got, _ := s.AllocPorts("/repo/b", 4000, 4099, 3); got != 4000 {
+		t.Errorf("got %d, want 4000 below 4005", got)
+	}
+}
@@ -0,0 +1,200 @@
+package tmux
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+
+	"deckard/internal/config"
+)
+
+// Deckard's tmux server reads the user's tmux.conf in ~/.config/deckard. That
+// file is the user's to edit; Deckard only creates it, sourcing managed.conf,
+// which Deckard rewrites from config on every session start.
+const (
+	userConfFile    = "tmux.conf"
+	managedConfFile = "managed.conf"
+
+	// generatedHeader starts the tmux.conf older versions of Deckard
+	// overwrote on every launch; such a file is replaced once.
+	generatedHeader = "# Deckard tmux config — do not edit manually"
+)
+
+// Fixed root-table keys of the managed config, besides the detach key.
+var fixedKeys = []string{"PageUp", "M-n", "M-s"}
+
+func confDir() (string, error) {
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return "", fmt.Errorf("user config dir: %w", err)
+	}
+	return filepath.Join(dir, "deckard"), nil
+}
+
+// configPath writes the managed config for settings and returns the path of
+// the user's tmux.conf, creating it if absent. When the managed config changes
+// under a running server, the server reloads the user's config.
+func configPath(settings config.Tmux) (string, error) {
+	if settings == (config.Tmux{}) {
+		settings = config.Default().Tmux
+	}
+	dir, err := confDir()
+	if err != nil {
+		return "", err
+	}
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return "", fmt.Errorf("mkdir: %w", err)
+	}
+	managed := filepath.Join(dir, managedConfFile)
+	user := filepath.Join(dir, userConfFile)
+
+	conf := managedConf(settings)
+	old, _ := os.ReadFile(managed)
+	changed := !bytes.Equal(old, conf)
+	if changed {
+		if err := os.WriteFile(managed, conf, 0644); err != nil {
+			return "", fmt.Errorf("write config: %w", err)
+		}
+	}
+
+	existing, err := os.ReadFile(user)
+	if err != nil || bytes.HasPrefix(existing, []byte(generatedHeader)) {
+		if err := os.WriteFile(user, userConf(managed), 0644); err != nil {
+			return "", fmt.Errorf("write config: %w", err)
+		}
+	}
+
+	if changed && exec.Command("tmux", "-L", socketName, "has-session").Run() == nil {
+		_ = exec.Command("tmux", "-L", socketName, "source-file", user).Run()
+	}
+	return user, nil
+}
+
+// userConf is the starting point of the user's tmux.conf.
+func userConf(managed string) []byte {
+	return []byte("# tmux config for Deckard sessions. Deckard creates this file but never\n" +
+		"# changes it: add your own settings below. Deckard's own settings live in\n" +
+		"# managed.conf and come from the \"tmux\" section of Deckard's config.\n" +
+		"source-file '" + managed + "'\n")
+}
+
+// managedConf renders Deckard's settings. The detach key returns to the
+// dashboard without stopping the agent, M-n jumps to the next session waiting
+// for input and M-s picks a session from a popup. The full status bar shows how
+// many other sessions are waiting, as counted by `deckard status`.
+func managedConf(s config.Tmux) []byte {
+	deckard, err := os.Executable()
+	if err != nil {
+		deckard = "deckard"
+	}
+	var b strings.Builder
+	b.WriteString("# Managed by Deckard — rewritten on every session start; edit tmux.conf instead\n")
+	b.WriteString("bind-key -n " + s.DetachKey + " detach-client\n")
+	if s.Mouse {
+		b.WriteString("set -g mouse on\n")
+	} else {
+		b.WriteString("set -g mouse off\n")
+	}
+	b.WriteString("bind-key -n PageUp copy-mode\n")
+	b.WriteString("bind-key -n M-n run-shell -b \"'" + deckard + "' next --client '#{client_name}' --session '#{session_name}'\"\n")
+	b.WriteString("bind-key -n M-s display-popup -E -w 70 -h 20 \"'" + deckard + "' pick --client '#{client_name}' --session '#{session_name}'\"\n")
+
+	waiting := "#('" + deckard + "' status --session '#{session_name}')"
+	switch s.Status {
+	case "off":
+		b.WriteString("set -g status off\n")
+		return []byte(b.String())
+	case "minimal":
+		b.WriteString("set -g status-right \"" + waiting + "\"\n")
+	default:
+		b.WriteString("set -g status-right \"" + waiting +
+			"#[fg=colour86]alt+n#[fg=colour240]  next waiting  " +
+			"#[fg=colour86]alt+s#[fg=colour240]  switch  " +
+			"#[fg=colour86]" + KeyLabel(s.DetachKey) + "#[fg=colour240]  return to deckard\"\n")
+	}
+	b.WriteString("set -g status on\n" +
+		"set -g status-style \"fg=colour240,bg=colour234\"\n" +
+		"set -g status-left \"\"\n" +
+		"set -g status-interval 5\n" +
+		"set -g status-right-length 100\n" +
+		"set -g status-justify left\n")
+	return []byte(b.String())
+}
+
+// KeyLabel renders a tmux key name the way the status bar shows keys,
+// e.g. C-] as ctrl+].
+func KeyLabel(key string) string {
+	r := strings.NewReplacer("C-", "ctrl+", "M-", "alt+", "S-", "shift+")
+	return r.Replace(key)
+}
+
+// CheckConfig reports problems with the user's tmux.conf for settings: keys it
+// binds in the root table that Deckard binds too, and a missing source-file of
+// the managed config. A tmux.conf that doesn't exist yet is fine.
+func CheckConfig(settings config.Tmux) []string {
+	if settings == (config.Tmux{}) {
+		settings = config.Default().Tmux
+	}
+	dir, err := confDir()
+	if err != nil {
+		return nil
+	}
+	f, err := os.Open(filepath.Join(dir, userConfFile))
+	if err != nil {
+		return nil
+	}
+	defer f.Close()
+
+	ours := map[string]bool{settings.DetachKey: true}
+	for _, k := range fixedKeys {
+		ours[k] = true
+	}
+	var problems []string
+	sourced := false
+	sc := bufio.NewScanner(f)
+	for line := 1; sc.Scan(); line++ {
+		fields := strings.Fields(sc.Text())
+		if len(fields) == 0 || strings.HasPrefix(fields[0], "#") {
+			continue
+		}
+		switch fields[0] {
+		case "source-file", "source":
+			if strings.Contains(sc.Text(), managedConfFile) {
+				sourced = true
+			}
+		case "bind-key", "bind":
+			if key, root := boundKey(fields[1:]); root && ours[key] {
+				problems = append(problems, fmt.Sprintf("tmux.conf:%d binds %s, which deckard uses", line, key))
+			}
+		}
+	}
+	if !sourced {
+		problems = append(problems, "tmux.conf doesn't source "+managedConfFile+"; deckard's keys and status bar are off")
+	}
+	return problems
+}
+
+// boundKey returns the key of a bind-key command's arguments and whether it
+// is bound in the root table.
+func boundKey(args []string) (key string, root bool) {
+	for i := 0; i < len(args); i++ {
+		a := args[i]
+		switch {
+		case a == "-n":
+			root = true
+		case a == "-T" && i+1 < len(args):
+			i++
+			root = args[i] == "root"
+		case a == "-N" && i+1 < len(args):
+			i++ // note
+		case strings.HasPrefix(a, "-"):
+		default:
+			return a, root
+		}
+	}
+	return "", false
+}
@@ -0,0 +1,29 @@
+package tmux
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestBoundKey(t *testing.T) {
+	tests := []struct {
+		line string
+		key  string
+		root bool
+	}{
+		{"M-n next-window", "M-n", false},
+		{"-n M-n next-window", "M-n", true},
+		{"-T root M-s display-popup", "M-s", true},
+		{"-T copy-mode-vi v send -X begin-selection", "v", false},
+		{"-r -n PageUp copy-mode -u", "PageUp", true},
+		{"-N note -n PageUp copy-mode", "PageUp", true},
+		{"-n", "", false},
+		{"", "", false},
+	}
+	for _, tt := range tests {
+		key, root := boundKey(strings.Fields(tt.line))
+		if key != tt.key || root != tt.root {
+			t.Errorf("boundKey(%q) = %q, %v; want %q, %v", tt.line, key, root, tt.key, tt.root)
+		}
+	}
+}
@@ -6,7 +6,15 @@ import (
 	"os"
 	"os/exec"
 	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
 	"time"
+
+	"deckard/internal/config"
+	"deckard/internal/model"
+	"deckard/internal/store"
 )
 
 const socketName = "deckard"
@@ -16,73 +24,390 @@ func SessionExists(slug string) bool {
 	return exec.Command("tmux", "-L", socketName, "has-session", "-t", slug).Run() == nil
 }
 
+// KillSession stops the named session and its agent. A missing session is not
+// an error. The agent being killed doesn't count as it exiting.
+func KillSession(slug string) error {
+	if !SessionExists(slug) {
+		clearExitStatus(slug)
+		return nil
+	}
+	out, err := exec.Command("tmux", "-L", socketName, "kill-session", "-t", slug).CombinedOutput()
+	if err != nil {
+		return fmt.Errorf("kill-session: %s", strings.TrimSpace(string(out)))
+	}
+	clearExitStatus(slug)
+	return nil
+}
+
+// The agent runs under a wrapper that records its exit status in a file, so
+// a crash can be told from a clean finish after the pane is gone.
+const exitWrapper = `f=$1; shift; "$@"; s=$?; echo $s > "$f"; exit $s`
+
+func exitPath(slug string) (string, error) {
+	dir, err := store.DataDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, "exit", slug), nil
+}
+
+// ExitStatus returns the exit status of the agent in the named session, if
+// it has exited since it was last started.
+func ExitStatus(slug string) (int, bool) {
+	p, err := exitPath(slug)
+	if err != nil {
+		return 0, false
+	}
+	data, err := os.ReadFile(p)
+	if err != nil {
+		return 0, false
+	}
+	status, err := strconv.Atoi(strings.TrimSpace(string(data)))
+	if err != nil {
+		return 0, false
+	}
+	return status, true
+}
+
+func clearExitStatus(slug string) {
+	if p, err := exitPath(slug); err == nil {
+		_ = os.Remove(p)
+	}
+}
+
+// agentArgs returns the wrapped agent command for opts, clearing any earlier
+// exit status.
+func agentArgs(slug string, opts Options) ([]string, error) {
+	command := opts.Command
+	if len(command) == 0 {
+		command = defaultAgent
+	}
+	p, err := exitPath(slug)
+	if err != nil {
+		return nil, err
+	}
+	if err := os.MkdirAll(filepath.Dir(p), 0755); err != nil {
+		return nil, fmt.Errorf("mkdir: %w", err)
+	}
+	clearExitStatus(slug)
+	args := append([]string{"sh", "-c", exitWrapper, "deckard-agent", p}, command...)
+	if opts.Prompt != "" {
+		args = append(args, opts.Prompt)
+	}
+	return args, nil
+}
+
+// AgentWindow is the name of the window the agent runs in.
+const AgentWindow = "agent"
+
+// agentTarget is the tmux target of the agent's pane, whichever window is
+// active. Once the agent exits the target no longer resolves, rather than
+// falling through to a layout window.
+func agentTarget(slug string) string { return slug + ":" + AgentWindow }
+
+// AgentRunning reports whether the named session has a live agent window.
+func AgentRunning(slug string) bool {
+	if _, exited := ExitStatus(slug); exited {
+		return false
+	}
+	out, err := exec.Command("tmux", "-L", socketName, "list-windows", "-t", slug, "-F", "#{window_name}").Output()
+	if err != nil {
+		return false
+	}
+	for _, name := range strings.Fields(string(out)) {
+		if name == AgentWindow {
+			return true
+		}
+	}
+	return false
+}
+
 // NeedsInput reports whether the named session is idle and awaiting input.
 // It takes two pane snapshots 300 ms apart: a static pane means Claude has
 // finished and is waiting; a changing pane means Claude is still processing.
+// A session without an agent window is not waiting.
 func NeedsInput(slug string) bool {
-	snap := func() []byte {
-		out, _ := exec.Command("tmux", "-L", socketName,
-			"capture-pane", "-t", slug, "-p", "-J").Output()
-		return out
+	snap := func() ([]byte, error) {
+		return exec.Command("tmux", "-L", socketName,
+			"capture-pane", "-t", agentTarget(slug), "-p", "-J").Output()
+	}
+	a, err := snap()
+	if err != nil {
+		return false
 	}
-	a := snap()
 	time.Sleep(300 * time.Millisecond)
-	b := snap()
-	return bytes.Equal(a, b)
+	b, err := snap()
+	return err == nil && bytes.Equal(a, b)
 }
 
-// configPath returns the Deckard tmux config path, writing defaults if absent.
-// The config adds F12 as a no-prefix detach key so users can return to Deckard
-// without needing to know tmux shortcuts.
-func configPath() (string, error) {
-	dir, err := os.UserConfigDir()
+// Session is a session on the Deckard socket.
+type Session struct {
+	Name string
+	Path string // directory the session was started in
+}
+
+// ListSessions returns all sessions on the Deckard socket, in name order.
+func ListSessions() ([]Session, error) {
+	out, err := exec.Command("tmux", "-L", socketName, "list-sessions",
+		"-F", "#{session_name}\t#{session_path}").Output()
 	if err != nil {
-		return "", fmt.Errorf("user config dir: %w", err)
+		// No server running means no sessions.
+		return nil, nil
 	}
-	p := filepath.Join(dir, "deckard", "tmux.conf")
-	if err := os.MkdirAll(filepath.Dir(p), 0755); err != nil {
-		return "", fmt.Errorf("mkdir: %w", err)
-	}
-	const conf = "# Deckard tmux config — do not edit manually\n" +
-		"# Ctrl+] returns you to the Deckard dashboard without stopping Claude\n" +
-		"bind-key -n C-] detach-client\n" +
-		"# Mouse wheel / PageUp enters scroll mode so you can read long plans\n" +
-		"set -g mouse on\n" +
-		"bind-key -n PageUp copy-mode\n" +
-		"set -g status on\n" +
-		"set -g status-style \"fg=colour240,bg=colour234\"\n" +
-		"set -g status-left \"\"\n" +
-		"set -g status-right \"#[fg=colour86]ctrl+]#[fg=colour240]  return to deckard\"\n" +
-		"set -g status-right-length 30\n" +
-		"set -g status-justify left\n"
-	if err := os.WriteFile(p, []byte(conf), 0644); err != nil {
-		return "", fmt.Errorf("write config: %w", err)
-	}
-	return p, nil
+	var sessions []Session
+	for _, line := range strings.Split(strings.TrimSpace(string(out)), "\n") {
+		name, path, _ := strings.Cut(line, "\t")
+		if name != "" {
+			sessions = append(sessions, Session{Name: name, Path: path})
+		}
+	}
+	sort.Slice(sessions, func(i, j int) bool { return sessions[i].Name < sessions[j].Name })
+	return sessions, nil
+}
+
+// Waiting reports, for each named session, whether its agent needs input.
+// The sessions are checked concurrently.
+func Waiting(names []string) []bool {
+	waiting := make([]bool, len(names))
+	var wg sync.WaitGroup
+	for i, n := range names {
+		wg.Add(1)
+		go func(i int, n string) {
+			defer wg.Done()
+			waiting[i] = NeedsInput(n)
+		}(i, n)
+	}
+	wg.Wait()
+	return waiting
+}
+
+// NextWaiting returns the first session after current, in name order and
+// wrapping around, whose agent needs input. current itself is checked last.
+func NextWaiting(current string) (string, bool) {
+	sessions, _ := ListSessions()
+	start := 0
+	for i, s := range sessions {
+		if s.Name == current {
+			start = i + 1
+		}
+	}
+	var order []string
+	for i := range sessions {
+		order = append(order, sessions[(start+i)%len(sessions)].Name)
+	}
+	for i, w := range Waiting(order) {
+		if w {
+			return order[i], true
+		}
+	}
+	return "", false
+}
+
+// SwitchClient moves an attached tmux client to the named session.
+func SwitchClient(client, slug string) error {
+	args := []string{"-L", socketName, "switch-client", "-t", slug}
+	if client != "" {
+		args = append(args, "-c", client)
+	}
+	out, err := exec.Command("tmux", args...).CombinedOutput()
+	if err != nil {
+		return fmt.Errorf("switch-client: %s", strings.TrimSpace(string(out)))
+	}
+	return nil
+}
+
+// DisplayMessage shows msg in the status line of a tmux client.
+func DisplayMessage(client, msg string) {
+	args := []string{"-L", socketName, "display-message"}
+	if client != "" {
+		args = append(args, "-c", client)
+	}
+	_ = exec.Command("tmux", append(args, msg)...).Run()
+}
+
+// defaultAgent is the command run when Options.Command is empty.
+var defaultAgent = []string{"claude", "--dangerously-skip-permissions"}
+
+// Options control how a new session's agent is launched.
+type Options struct {
+	Command []string        // agent command; defaults to claude
+	Prompt  string          // initial prompt appended to the command; empty to start interactively
+	Tmux    config.Tmux     // managed tmux settings; the zero value means the defaults
+	Layout  []config.Window // windows opened next to the agent's
+	Env     []string        // KEY=value pairs set in the session's environment
 }
 
 // EnsureSession creates a detached session running claude in path if one does
-// not already exist. Idempotent: safe to call before every attach.
-func EnsureSession(slug, path string) error {
+// not already exist, with the agent in the first window and opts.Layout's
+// windows after it. If the session outlived its agent, the agent is started
+// again in a new first window. Idempotent: safe to call before every attach.
+func EnsureSession(slug, path string, opts Options) error {
 	if SessionExists(slug) {
+		if AgentRunning(slug) {
+			return nil
+		}
+		command, err := agentArgs(slug, opts)
+		if err != nil {
+			return err
+		}
+		args := append([]string{"-L", socketName, "new-window", "-b", "-t", slug + ":^",
+			"-n", AgentWindow, "-c", path}, command...)
+		if out, err := exec.Command("tmux", args...).CombinedOutput(); err != nil {
+			return fmt.Errorf("new-window: %s", strings.TrimSpace(string(out)))
+		}
 		return nil
 	}
-	cfgPath, err := configPath()
+	cfgPath, err := configPath(opts.Tmux)
 	if err != nil {
 		return err
 	}
-	cmd := exec.Command("tmux", "-L", socketName, "-f", cfgPath,
-		"new-session", "-d", "-s", slug, "-c", path,
-		"claude", "--dangerously-skip-permissions")
+	command, err := agentArgs(slug, opts)
+	if err != nil {
+		return err
+	}
+	args := []string{"-L", socketName, "-f", cfgPath,
+		"new-session", "-d", "-s", slug, "-n", AgentWindow, "-c", path}
+	for _, kv := range opts.Env {
+		args = append(args, "-e", kv)
+	}
+	args = append(args, command...)
+	cmd := exec.Command("tmux", args...)
 	if out, err := cmd.CombinedOutput(); err != nil {
 		return fmt.Errorf("new-session: %s", out)
 	}
+	if err := openLayout(slug, path, opts.Layout); err != nil {
+		return err
+	}
+	return nil
+}
+
+// openLayout opens the layout's windows and panes in the background. Panes
+// stay open when their command exits, so its status can be read; each window
+// starts with a shell until that is set, then runs its command.
+func openLayout(slug, path string, layout []config.Window) error {
+	run := func(verb string, args ...string) (string, error) {
+		out, err := exec.Command("tmux", append([]string{"-L", socketName, verb}, args...)...).CombinedOutput()
+		if err != nil {
+			return "", fmt.Errorf("%s: %s", verb, strings.TrimSpace(string(out)))
+		}
+		return strings.TrimSpace(string(out)), nil
+	}
+	for _, w := range layout {
+		id, err := run("new-window", "-d", "-P", "-F", "#{window_id}", "-t", slug+":", "-n", w.Name, "-c", path)
+		if err != nil {
+			return err
+		}
+		if _, err := run("set-option", "-w", "-t", id, "remain-on-exit", "on"); err != nil {
+			return err
+		}
+		if w.Cmd != "" {
+			if _, err := run("respawn-pane", "-k", "-t", id, "-c", path, w.Cmd); err != nil {
+				return err
+			}
+		}
+		for _, p := range w.Panes {
+			args := []string{"-d", "-t", id, "-c", path}
+			if p != "" {
+				args = append(args, p)
+			}
+			if _, err := run("split-window", args...); err != nil {
+				return err
+			}
+		}
+	}
 	return nil
 }
 
+// Windows returns the windows of the named session in index order, each as
+// seen through its first pane.
+func Windows(slug string) []model.Window {
+	out, err := exec.Command("tmux", "-L", socketName, "list-panes", "-s", "-t", slug, "-F",
+		"#{window_index}\t#{pane_index}\t#{window_name}\t#{pane_current_command}\t#{pane_dead}\t#{pane_dead_status}").Output()
+	if err != nil {
+		return nil
+	}
+	var windows []model.Window
+	seen := map[int]bool{}
+	for _, line := range strings.Split(strings.TrimRight(string(out), "\n"), "\n") {
+		f := strings.Split(line, "\t")
+		if len(f) < 6 {
+			continue
+		}
+		idx, _ := strconv.Atoi(f[0])
+		if seen[idx] {
+			continue
+		}
+		seen[idx] = true
+		status, _ := strconv.Atoi(f[5])
+		windows = append(windows, model.Window{
+			Index:      idx,
+			Name:       f[2],
+			Command:    f[3],
+			Dead:       f[4] == "1",
+			ExitStatus: status,
+		})
+	}
+	sort.Slice(windows, func(i, j int) bool { return windows[i].Index < windows[j].Index })
+	return windows
+}
+
+// SelectWindow makes the window at index the active one of the session, so
+// the next attach lands on it.
+func SelectWindow(slug string, index int) error {
+	out, err := exec.Command("tmux", "-L", socketName, "select-window",
+		"-t", fmt.Sprintf("%s:%d", slug, index)).CombinedOutput()
+	if err != nil {
+		return fmt.Errorf("select-window: %s", strings.TrimSpace(string(out)))
+	}
+	return nil
+}
+
+// SelectAgent makes the agent's window the active window of the session, so
+// attaching lands on the agent rather than whichever window was last used.
+func SelectAgent(slug string) error {
+	out, err := exec.Command("tmux", "-L", socketName, "select-window",
+		"-t", agentTarget(slug)).CombinedOutput()
+	if err != nil {
+		return fmt.Errorf("select-window: %s", strings.TrimSpace(string(out)))
+	}
+	return nil
+}
+
+// SendPrompt pastes text into the session's active pane and submits it.
+// Bracketed paste keeps multi-line prompts together as a single message.
+func SendPrompt(slug, text string) error {
+	buf := "deckard-" + slug
+	load := exec.Command("tmux", "-L", socketName, "load-buffer", "-b", buf, "-")
+	load.Stdin = strings.NewReader(text)
+	if out, err := load.CombinedOutput(); err != nil {
+		return fmt.Errorf("load-buffer: %s", strings.TrimSpace(string(out)))
+	}
+	paste := exec.Command("tmux", "-L", socketName, "paste-buffer", "-d", "-p", "-b", buf, "-t", agentTarget(slug))
+	if out, err := paste.CombinedOutput(); err != nil {
+		return fmt.Errorf("paste-buffer: %s", strings.TrimSpace(string(out)))
+	}
+	// Give the agent a moment to ingest the paste before pressing Enter.
+	time.Sleep(200 * time.Millisecond)
+	if out, err := exec.Command("tmux", "-L", socketName, "send-keys", "-t", agentTarget(slug), "Enter").CombinedOutput(); err != nil {
+		return fmt.Errorf("send-keys: %s", strings.TrimSpace(string(out)))
+	}
+	return nil
+}
+
+// Deliver hands a prompt to the agent in slug, starting the agent in path
+// with opts and the prompt as its first message if it is not running, even
+// when the session's other windows are.
+func Deliver(slug, path, text string, opts Options) error {
+	if AgentRunning(slug) {
+		return SendPrompt(slug, text)
+	}
+	opts.Prompt = text
+	return EnsureSession(slug, path, opts)
+}
+
 // AttachCmd returns a command that attaches the terminal to a named session.
 // Pass the result to tea.ExecProcess — Deckard resumes when the user detaches
-// (F12) or when Claude exits naturally.
+// (the detach key) or when Claude exits naturally.
 func AttachCmd(slug string) *exec.Cmd {
 	return exec.Command("tmux", "-L", socketName, "attach-session", "-t", slug)
 }
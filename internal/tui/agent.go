@@ -0,0 +1,69 @@
+package tui
+
+import (
+	tea "github.com/charmbracelet/bubbletea"
+
+	"deckard/internal/claude"
+	"deckard/internal/model"
+	"deckard/internal/tmux"
+)
+
+type sessionKilledMsg struct {
+	slug string
+	err  error
+}
+
+// agentOptions returns the tmux options for starting s's own agent profile,
+// falling back to the default profile when it has none or it is gone.
+func (m Model) agentOptions(s model.Session) tmux.Options {
+	opts := m.launchOptions(s.Path, "")
+	if command, err := m.cfg.AgentCommand(s.Meta.Agent); err == nil {
+		opts.Command = command
+	}
+	return opts
+}
+
+// resumeArgs returns the agent arguments that resume the latest conversation
+// in path, or nil if there is none.
+func resumeArgs(path string) []string {
+	if id, err := claude.LatestConversation(path); err == nil && id != "" {
+		return []string{"--resume", id}
+	}
+	return nil
+}
+
+func killSessionCmd(s model.Session) tea.Cmd {
+	return func() tea.Msg {
+		return sessionKilledMsg{slug: s.Slug, err: tmux.KillSession(s.Slug)}
+	}
+}
+
+// restartCmd stops s's session, if any, and starts it again with the agent
+// resuming its latest conversation, then attaches.
+func restartCmd(s model.Session, opts tmux.Options) tea.Cmd {
+	return func() tea.Msg {
+		if err := tmux.KillSession(s.Slug); err != nil {
+			return sessionEnsuredMsg{err: err}
+		}
+		opts.Command = append(opts.Command[:len(opts.Command):len(opts.Command)], resumeArgs(s.Path)...)
+		if err := tmux.EnsureSession(s.Slug, s.Path, opts); err != nil {
+			return sessionEnsuredMsg{err: err}
+		}
+		return sessionEnsuredMsg{slug: s.Slug}
+	}
+}
+
+func (m Model) handleAgentMsg(msg tea.Msg) (Model, tea.Cmd, bool) {
+	km, ok := msg.(sessionKilledMsg)
+	if !ok {
+		return m, nil, false
+	}
+	if km.err != nil {
+		m.setNotice(km.err.Error(), true)
+		return m, nil, true
+	}
+	m.forgetLive(km.slug)
+	m.setNotice("killed "+km.slug, false)
+	m.loading = true
+	return m, m.fetchSessions(), true
+}
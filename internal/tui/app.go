@@ -4,18 +4,25 @@ import (
 	"fmt"
 	"os/exec"
 	"runtime"
+	"strconv"
 	"strings"
 	"sync"
 	"time"
 
 	"github.com/charmbracelet/bubbles/list"
+	"github.com/charmbracelet/bubbles/textarea"
 	"github.com/charmbracelet/bubbles/textinput"
+	"github.com/charmbracelet/bubbles/viewport"
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/charmbracelet/lipgloss"
 
+	"deckard/internal/claude"
+	"deckard/internal/config"
 	"deckard/internal/git"
 	"deckard/internal/gitlab"
 	"deckard/internal/model"
+	"deckard/internal/spawn"
+	"deckard/internal/store"
 	"deckard/internal/tmux"
 )
 
@@ -29,6 +36,23 @@ const (
 	stateCommitType
 	stateCommit
 	stateDeleteConfirm
+	stateDeleteForce
+	statePipeline
+	stateThreads
+	stateRetireConfirm
+	stateRetireSummary
+	stateTrash
+	stateSetup
+	stateSpawnPath
+	stateSpawnReport
+	stateVariants
+	stateCompare
+	stateFork
+	stateStage
+	stateMeta
+	stateFilter
+	stateResume
+	stateHelp
 )
 
 // — conventional commit types ————————————————————————————————————————————————
@@ -110,9 +134,10 @@ type sessionsLoadedMsg struct {
 }
 
 type worktreeCreatedMsg struct {
-	slug string
-	path string
-	err  error
+	slug    string
+	path    string
+	fetched error // why the base could not be refreshed; nil if it was
+	err     error
 }
 
 type sessionEnsuredMsg struct {
@@ -132,6 +157,15 @@ type worktreeRemovedMsg struct {
 	err error
 }
 
+// deleteCheckedMsg is the pre-delete risk report for a worktree.
+type deleteCheckedMsg struct {
+	path         string
+	work         git.WorkState
+	agentRunning bool // a tmux session exists for the worktree
+	agentBusy    bool // …and its pane is still changing
+	err          error
+}
+
 // — list item ———————————————————————————————————————————————————————————————
 
 type sessionItem struct {
@@ -142,6 +176,8 @@ type sessionItem struct {
 func (i sessionItem) Title() string {
 	var indicator string
 	switch {
+	case i.s.Crashed():
+		indicator = "✕"
 	case i.s.NeedsInput:
 		indicator = "▲"
 	case i.s.TmuxRunning:
@@ -149,28 +185,126 @@ func (i sessionItem) Title() string {
 	default:
 		indicator = "·"
 	}
-	return indicator + " " + i.s.Slug
+	title := indicator + " " + i.s.Slug
+	if i.s.Meta.Pinned {
+		title += " ★"
+	}
+	if i.s.Meta.Priority > 0 {
+		title += fmt.Sprintf(" P%d", i.s.Meta.Priority)
+	}
+	return title
+}
+
+func (i sessionItem) Description() string {
+	switch {
+	case i.s.Variant != "":
+		return "variant of " + i.s.Variant
+	case i.s.Parent != "":
+		return "fork of " + i.s.Parent
+	}
+	return i.s.Branch
 }
 
-func (i sessionItem) Description() string { return i.s.Branch }
-func (i sessionItem) FilterValue() string  { return i.s.Slug }
+func (i sessionItem) FilterValue() string { return i.s.Slug }
 
 // — model ———————————————————————————————————————————————————————————————————
 
 type Model struct {
 	list     list.Model
 	sessions []model.Session
+	rows     []listRow       // what the list shows, in order
+	prefs    store.ListPrefs // used when there is no store
 	width    int
 	height   int
 	loading  bool
 	err      error
 	repoRoot string
+	cfg      config.Config
+	store    *store.Store // nil if the data dir is unavailable
+
+	notice    string // one-line feedback shown in the help bar until the next key
+	noticeErr bool
 
 	state        appState
+	board        bool // board layout: sessions in columns by stage
+	filter       sessionFilter
+	filterInput  textinput.Model
 	nameInput    textinput.Model
 	inputErr     string
 	spinnerFrame int
 	commitType   string
+	newMode      newMode
+	picker       picker
+	baseInput    textinput.Model // start point for a new branch; empty for the default
+	taskInput    textarea.Model  // optional first prompt for the agent
+	newField     newField
+	templateIdx  int // index into templateNames; -1 if none applied
+
+	pendingLaunch launch // how to start the agent of the worktree being created
+
+	killArmed      string // path of the session X was pressed once on
+	restartArmed   string // path of the running session R was pressed once on
+	restoreOffered bool   // the fleet restore has been offered since startup
+
+	resumeSession model.Session // session whose agent is being started
+	resumeWindow  int           // window to attach to afterwards; 0 for the agent's
+	resumeConvs   []claude.Conversation
+	resumeCursor  int // resumeFresh, resumeContinue, or resumeFixed+conversation
+
+	triage         bool            // attach to the next waiting session after each detach
+	triageSeen     map[string]bool // paths visited this triage round
+	triageNext     string          // path about to be attached
+	triageDetached bool            // back from a session; continue once refreshed
+
+	spawnRunning bool
+	spawnResults []spawn.Result
+
+	variantField   variantField
+	countInput     textinput.Model // number of variants to create
+	compareGroup   string
+	compareStats   map[string]variantStats // by worktree path; nil while loading
+	compareTests   map[string]testResult   // by worktree path
+	compareTesting bool
+	compareCursor  int
+	compareArmed   bool // w was pressed once on the selected variant
+	compareErr     string
+
+	metaPath  string     // worktree whose metadata is being edited
+	metaEdit  model.Meta // priority and pin as edited so far
+	metaField metaField
+
+	forkParent model.Session // session being forked
+	forkResume bool          // copy the parent's conversation into the fork
+
+	setupSession model.Session // worktree being set up
+	setupLog     []string
+	setupErr     string
+	setupDone    bool
+	setupCh      chan tea.Msg
+
+	pipeline        *model.Pipeline
+	pipelineLoading bool
+	pipelineErr     string
+	pipelineNote    string
+	pipelineCursor  int
+	pipelineLogJob  int // job whose log is shown; 0 if none
+	pipelineLog     viewport.Model
+
+	threads        []model.Thread
+	threadsLoading bool
+	threadsErr     string
+	threadsNote    string
+	threadCursor   int
+	threadMarked   map[string]bool // thread IDs marked as addressed
+
+	deleteCheck *deleteCheckedMsg // nil while the pre-delete checks run
+
+	trashCursor     int
+	trashPurgeArmed bool // x was pressed once on the selected archive
+	trashErr        string
+
+	retireResult  *retireResultMsg
+	retireSkipped map[string]bool // worktree paths auto-retire has already tried
 }
 
 func New() Model {
@@ -202,17 +336,99 @@ func New() Model {
 	ti.Placeholder = "e.g. phase-2-gitlab-mr-linking"
 	ti.CharLimit = 100
 
-	return Model{
-		list:      l,
-		repoRoot:  root,
-		loading:   true,
-		nameInput: ti,
+	bi := textinput.New()
+	bi.Placeholder = "origin/" + git.DefaultBranch(root) + " (fetched)"
+	bi.CharLimit = 100
+
+	fi := textinput.New()
+	fi.Prompt = "? "
+	fi.Placeholder = "text, state:needs-input, ci:failed, mr:merged, tag:x, dirty"
+	fi.CharLimit = 200
+
+	ci := textinput.New()
+	ci.CharLimit = 1
+	ci.Validate = func(s string) error {
+		if _, err := strconv.Atoi(s); s != "" && err != nil {
+			return err
+		}
+		return nil
 	}
+
+	ta := textarea.New()
+	ta.Placeholder = "optional — first prompt for claude"
+	ta.ShowLineNumbers = false
+	ta.SetWidth(50)
+	ta.SetHeight(4)
+
+	m := Model{
+		list:          l,
+		repoRoot:      root,
+		loading:       true,
+		nameInput:     ti,
+		baseInput:     bi,
+		countInput:    ci,
+		filterInput:   fi,
+		taskInput:     ta,
+		templateIdx:   -1,
+		pendingLaunch: launch{attach: true},
+		retireSkipped: map[string]bool{},
+	}
+
+	cfg, err := config.Load(root)
+	if err != nil {
+		m.setNotice(err.Error(), true)
+	}
+	m.cfg = cfg
+	if st, err := store.Open(root); err != nil {
+		m.setNotice(err.Error(), true)
+	} else {
+		m.store = st
+	}
+	if problems := tmux.CheckConfig(cfg.Tmux); len(problems) > 0 && m.notice == "" {
+		m.setNotice(strings.Join(problems, " · "), true)
+	}
+	return m
+}
+
+// launchOptions returns the tmux options for starting the default agent in
+// the worktree at path, with an optional first prompt. A default_agent that
+// names no profile falls back to the built-in one, so Command is never empty
+// and callers can append arguments to it.
+func (m Model) launchOptions(path, prompt string) tmux.Options {
+	return tmux.Options{
+		Command: m.cfg.DefaultAgentCommand(),
+		Prompt:  prompt,
+		Tmux:    m.cfg.Tmux,
+		Layout:  m.cfg.Layout,
+		Env:     m.portEnv(path),
+	}
+}
+
+func (m *Model) setNotice(text string, isErr bool) {
+	m.notice = text
+	m.noticeErr = isErr
 }
 
 // — commands ————————————————————————————————————————————————————————————————
 
-func fetchSessions() tea.Msg {
+// fetchSessions lists the worktrees and gathers their state. Everything that
+// blocks — git, tmux, glab, transcripts, port probes — happens here, off the
+// UI; Update only records the result. Ports are probed for the blocks
+// reserved when the command is built.
+func (m Model) fetchSessions() tea.Cmd {
+	blocks := map[string]int{}
+	if m.store != nil {
+		for path, block := range m.store.Ports {
+			blocks[path] = block.Start
+		}
+	}
+	ports := m.cfg.Ports
+	return func() tea.Msg {
+		return loadSessions(blocks, ports)
+	}
+}
+
+func loadSessions(blocks map[string]int, ports config.Ports) tea.Msg {
 	sessions, err := git.ListWorktrees()
 	if err != nil {
 		return sessionsLoadedMsg{sessions: nil, err: err}
@@ -225,14 +441,36 @@ func fetchSessions() tea.Msg {
 		go func(i int) {
 			defer wg.Done()
 			sessions[i].TmuxRunning = tmux.SessionExists(sessions[i].Slug)
+			sessions[i].ExitStatus, sessions[i].AgentExited = tmux.ExitStatus(sessions[i].Slug)
 			if sessions[i].TmuxRunning {
-				sessions[i].NeedsInput = tmux.NeedsInput(sessions[i].Slug)
+				if !sessions[i].AgentExited {
+					sessions[i].NeedsInput = tmux.NeedsInput(sessions[i].Slug)
+					sessions[i].Conversation, _ = claude.LatestConversation(sessions[i].Path)
+				}
+				sessions[i].Windows = tmux.Windows(sessions[i].Slug)
+			}
+			if base := git.BranchBase(sessions[i].Path, sessions[i].Branch); base != "" {
+				sessions[i].Base = base
+				sessions[i].Ahead, sessions[i].Behind, _ = git.AheadBehind(sessions[i].Path, base)
+			}
+			if start, ok := blocks[sessions[i].Path]; ok {
+				sessions[i].Ports = namedPorts(ports, start, true)
+			}
+			sessions[i].Uncommitted, _ = git.Uncommitted(sessions[i].Path)
+			sessions[i].LastActive, _ = git.CommitTime(sessions[i].Path)
+			if t, _ := claude.LastActive(sessions[i].Path); t.After(sessions[i].LastActive) {
+				sessions[i].LastActive = t
 			}
+			sessions[i].Variant = git.BranchVariant(sessions[i].Path, sessions[i].Branch)
+			sessions[i].Parent = git.BranchParent(sessions[i].Path, sessions[i].Branch)
 			mr, _ := gitlab.FetchMR(sessions[i].Branch)
 			sessions[i].MR = mr
 			if mr != nil {
 				sessions[i].NeedsInput = mr.PipelineStatus == "failed" || mr.HasUnresolved
 			}
+			if sessions[i].Crashed() {
+				sessions[i].NeedsInput = true
+			}
 		}(i)
 	}
 	wg.Wait()
@@ -240,20 +478,56 @@ func fetchSessions() tea.Msg {
 	return sessionsLoadedMsg{sessions: sessions, err: nil}
 }
 
-func createWorktreeCmd(repoRoot, branch string) tea.Cmd {
+func createWorktreeCmd(repoRoot, branch, base string) tea.Cmd {
+	return func() tea.Msg {
+		fetched := git.FetchBase(repoRoot, base)
+		path, err := git.CreateWorktree(repoRoot, branch, base)
+		return worktreeCreatedMsg{
+			slug:    git.BranchToSlug(branch),
+			path:    path,
+			fetched: fetched,
+			err:     err,
+		}
+	}
+}
+
+func checkoutWorktreeCmd(repoRoot string, it pickItem) tea.Cmd {
 	return func() tea.Msg {
-		path, err := git.CreateWorktree(repoRoot, branch)
+		path, err := git.CheckoutWorktree(repoRoot, it.branch, it.mrIID)
 		return worktreeCreatedMsg{
-			slug: git.BranchToSlug(branch),
+			slug: git.BranchToSlug(it.branch),
 			path: path,
 			err:  err,
 		}
 	}
 }
 
-func ensureAndAttachCmd(s model.Session) tea.Cmd {
+// ensureAndAttachCmd starts the session if needed and attaches to its agent
+// window.
+func ensureAndAttachCmd(s model.Session, opts tmux.Options) tea.Cmd {
 	return func() tea.Msg {
-		if err := tmux.EnsureSession(s.Slug, s.Path); err != nil {
+		if err := tmux.EnsureSession(s.Slug, s.Path, opts); err != nil {
+			return sessionEnsuredMsg{err: err}
+		}
+		if err := tmux.SelectAgent(s.Slug); err != nil {
+			return sessionEnsuredMsg{err: err}
+		}
+		return sessionEnsuredMsg{slug: s.Slug}
+	}
+}
+
+// attachWindowCmd starts the session if needed and attaches to the window at
+// position n (1-based) of its layout.
+func attachWindowCmd(s model.Session, opts tmux.Options, n int) tea.Cmd {
+	return func() tea.Msg {
+		if err := tmux.EnsureSession(s.Slug, s.Path, opts); err != nil {
+			return sessionEnsuredMsg{err: err}
+		}
+		windows := tmux.Windows(s.Slug)
+		if n > len(windows) {
+			return sessionEnsuredMsg{err: fmt.Errorf("%s has %d window(s)", s.Slug, len(windows))}
+		}
+		if err := tmux.SelectWindow(s.Slug, windows[n-1].Index); err != nil {
 			return sessionEnsuredMsg{err: err}
 		}
 		return sessionEnsuredMsg{slug: s.Slug}
@@ -276,14 +550,44 @@ func commitCmd(path, message string) tea.Cmd {
 	}
 }
 
-// buildItems rebuilds the list items with the current spinner frame.
+// buildItems rebuilds the list items from the sessions that pass the
+// filter, with the current spinner frame. The selection stays on the same
+// session where it can.
 func (m *Model) buildItems() {
-	char := spinnerFrames[m.spinnerFrame]
-	items := make([]list.Item, len(m.sessions))
-	for i, s := range m.sessions {
-		items[i] = sessionItem{s: s, spinnerChar: char}
+	m.buildItemsAt(m.selectedPath())
+}
+
+// buildItemsAt rebuilds the list items like buildItems, putting the
+// selection on the session at path where it can. Callers that replace
+// m.sessions read the path first, since the rows still index the old
+// sessions until they are rebuilt.
+func (m *Model) buildItemsAt(selected string) {
+	m.rows = m.layoutRows()
+	m.list.SetItems(m.listItems())
+	if n := len(m.rows); n > 0 && m.list.Index() >= n {
+		m.list.Select(n - 1)
+	}
+	for pos, r := range m.rows {
+		if r.session >= 0 && m.sessions[r.session].Path == selected && pos != m.list.Index() {
+			m.list.Select(pos)
+		}
+	}
+
+	p := m.listPrefs()
+	title := "WORKTREES  " + sortOrDefault(p.Sort)
+	if p.Group != groupNone && !m.board {
+		title += " · by " + p.Group
+	}
+	if m.filter.active() {
+		shown := 0
+		for _, r := range m.rows {
+			if r.session >= 0 {
+				shown++
+			}
+		}
+		title += fmt.Sprintf("  %s  %d/%d", m.filter.query, shown, len(m.sessions))
 	}
-	m.list.SetItems(items)
+	m.list.Title = title
 }
 
 func openURLCmd(url string) tea.Cmd {
@@ -302,9 +606,26 @@ func openURLCmd(url string) tea.Cmd {
 	}
 }
 
-func deleteWorktreeCmd(repoRoot, path, branch string) tea.Cmd {
+func checkDeleteCmd(s model.Session) tea.Cmd {
 	return func() tea.Msg {
-		err := git.DeleteWorktree(repoRoot, path, branch)
+		work, err := git.InspectWork(s.Path, s.Branch, mergedHead(s))
+		msg := deleteCheckedMsg{path: s.Path, work: work, err: err}
+		if tmux.SessionExists(s.Slug) {
+			msg.agentRunning = true
+			msg.agentBusy = !tmux.NeedsInput(s.Slug)
+		}
+		return msg
+	}
+}
+
+// deleteWorktreeCmd stops the worktree's tmux session, then removes the
+// worktree and its branch, so no session is left orphaned.
+func deleteWorktreeCmd(repoRoot string, s model.Session, force bool) tea.Cmd {
+	return func() tea.Msg {
+		if err := tmux.KillSession(s.Slug); err != nil {
+			return worktreeRemovedMsg{err: err}
+		}
+		err := git.DeleteWorktree(repoRoot, s.Path, s.Branch, force)
 		return worktreeRemovedMsg{err: err}
 	}
 }
@@ -312,7 +633,11 @@ func deleteWorktreeCmd(repoRoot, path, branch string) tea.Cmd {
 // — tea.Model ———————————————————————————————————————————————————————————————
 
 func (m Model) Init() tea.Cmd {
-	return tea.Batch(fetchSessions, tickCmd())
+	cmds := []tea.Cmd{m.fetchSessions(), tickCmd()}
+	if ids := m.expiredTrash(); len(ids) > 0 {
+		cmds = append(cmds, purgeTrashCmd(m.repoRoot, ids))
+	}
+	return tea.Batch(cmds...)
 }
 
 func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
@@ -322,6 +647,7 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		m.height = msg.Height
 		lw, lh := m.listDimensions()
 		m.list.SetSize(lw, lh)
+		m.sizePipelineLog()
 		return m, nil
 
 	case tickMsg:
@@ -338,8 +664,61 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			return m, nil
 		}
 		m.err = nil
+		selected := m.selectedPath()
 		m.sessions = msg.sessions
-		m.buildItems()
+		// Pick up what `deckard spawn` may have recorded meanwhile; every
+		// change made here is saved straight away, so nothing is lost.
+		if st, err := store.Open(m.repoRoot); err == nil {
+			m.store = st
+		}
+		m.applyMeta()
+		m.applyStages()
+		m.applyPorts()
+		m.recordLive()
+		m.buildItemsAt(selected)
+		cmds := append(m.autoFixCmds(), m.autoRetireCmd(), m.offerRestore())
+		if m.triageDetached {
+			m.triageDetached = false
+			cmds = append(cmds, m.continueTriage())
+		}
+		return m, tea.Batch(cmds...)
+
+	case retireResultMsg:
+		m.notice = ""
+		if len(msg.removed) == 0 && len(msg.skipped) == 0 {
+			return m, nil
+		}
+		// Don't pull the user out of another view; summarise in a notice.
+		if m.state == stateNormal {
+			m.retireResult = &msg
+			m.state = stateRetireSummary
+		} else {
+			m.setNotice(msg.summary(), len(msg.skipped) > 0)
+		}
+		if len(msg.removed) == 0 {
+			return m, nil
+		}
+		m.loading = true
+		return m, m.fetchSessions()
+
+	case ciFixSentMsg:
+		if msg.err != nil {
+			m.setNotice("fix CI ("+msg.slug+"): "+msg.err.Error(), true)
+			return m, nil
+		}
+		m.setNotice(fmt.Sprintf("sent %d failing job log(s) to %s", msg.jobs, msg.slug), false)
+		return m, nil
+
+	case pickItemsLoadedMsg:
+		if msg.mode != m.newMode {
+			return m, nil
+		}
+		if msg.err != nil {
+			m.picker.loading = false
+			m.picker.err = msg.err.Error()
+			return m, nil
+		}
+		m.picker.setItems(msg.items, m.nameInput.Value())
 		return m, nil
 
 	case worktreeCreatedMsg:
@@ -347,11 +726,46 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			m.inputErr = msg.err.Error()
 			return m, nil
 		}
-		m.state = stateNormal
 		m.inputErr = ""
 		m.nameInput.Reset()
 		m.nameInput.Blur()
-		return m, ensureAndAttachCmd(model.Session{Slug: msg.slug, Path: msg.path})
+		if msg.fetched != nil {
+			m.setNotice("branched from the last-fetched base: "+msg.fetched.Error(), true)
+		}
+		m.rememberNew(msg.path, model.Meta{
+			CreatedAt: time.Now(),
+			Agent:     m.cfg.DefaultAgent,
+			Prompt:    m.pendingLaunch.prompt,
+		})
+		return m.startSetup(model.Session{Slug: msg.slug, Path: msg.path})
+
+	case spawnDoneMsg:
+		m.spawnRunning = false
+		m.nameInput.Blur()
+		if msg.err != nil {
+			m.inputErr = msg.err.Error()
+			return m, nil
+		}
+		m.state = stateSpawnReport
+		m.spawnResults = msg.results
+		// The spawned sessions reserved their ports in the store meanwhile.
+		if st, err := store.Open(m.repoRoot); err == nil {
+			m.store = st
+		}
+		for _, r := range msg.results {
+			m.rememberNew(r.Path, r.Meta())
+		}
+		m.loading = true
+		return m, m.fetchSessions()
+
+	case sessionStartedMsg:
+		if msg.err != nil {
+			m.setNotice(msg.err.Error(), true)
+			return m, nil
+		}
+		m.setNotice("started "+msg.slug+" in the background", false)
+		m.loading = true
+		return m, m.fetchSessions()
 
 	case sessionEnsuredMsg:
 		if msg.err != nil {
@@ -364,8 +778,9 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 
 	case claudeExitedMsg:
 		// Claude exited — refresh the session list and return to the overview.
+		m.triageDetached = m.triage
 		m.loading = true
-		return m, fetchSessions
+		return m, m.fetchSessions()
 
 	case commitResultMsg:
 		if msg.err != nil {
@@ -377,18 +792,56 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		m.nameInput.Reset()
 		m.nameInput.Blur()
 		m.loading = true
-		return m, fetchSessions
+		return m, m.fetchSessions()
+
+	case deleteCheckedMsg:
+		if s := m.selectedSession(); s != nil && s.Path == msg.path {
+			m.deleteCheck = &msg
+		}
+		return m, nil
 
 	case worktreeRemovedMsg:
 		if msg.err != nil {
-			m.inputErr = msg.err.Error()
+			m.inputErr = ""
+			m.setNotice(msg.err.Error(), true)
 			m.state = stateNormal
 			return m, nil
 		}
 		m.state = stateNormal
 		m.inputErr = ""
 		m.loading = true
-		return m, fetchSessions
+		return m, m.fetchSessions()
+	}
+
+	if pm, cmd, ok := m.handlePipelineMsg(msg); ok {
+		return pm, cmd
+	}
+	if tm, cmd, ok := m.handleThreadsMsg(msg); ok {
+		return tm, cmd
+	}
+	if tm, cmd, ok := m.handleTrashMsg(msg); ok {
+		return tm, cmd
+	}
+	if sm, cmd, ok := m.handleSetupMsg(msg); ok {
+		return sm, cmd
+	}
+	if vm, cmd, ok := m.handleVariantsMsg(msg); ok {
+		return vm, cmd
+	}
+	if fm, cmd, ok := m.handleForkMsg(msg); ok {
+		return fm, cmd
+	}
+	if tm, cmd, ok := m.handleTriageMsg(msg); ok {
+		return tm, cmd
+	}
+	if am, cmd, ok := m.handleAgentMsg(msg); ok {
+		return am, cmd
+	}
+	if rm, cmd, ok := m.handleRestoreMsg(msg); ok {
+		return rm, cmd
+	}
+	if cm, cmd, ok := m.handleResumeMsg(msg); ok {
+		return cm, cmd
 	}
 
 	switch m.state {
@@ -400,6 +853,40 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		return m.updateCommit(msg)
 	case stateDeleteConfirm:
 		return m.updateDeleteConfirm(msg)
+	case stateDeleteForce:
+		return m.updateDeleteForce(msg)
+	case statePipeline:
+		return m.updatePipeline(msg)
+	case stateThreads:
+		return m.updateThreads(msg)
+	case stateRetireConfirm:
+		return m.updateRetireConfirm(msg)
+	case stateRetireSummary:
+		return m.updateRetireSummary(msg)
+	case stateHelp:
+		return m.updateHelp(msg)
+	case stateTrash:
+		return m.updateTrash(msg)
+	case stateSetup:
+		return m.updateSetup(msg)
+	case stateSpawnPath:
+		return m.updateSpawnPath(msg)
+	case stateSpawnReport:
+		return m.updateSpawnReport(msg)
+	case stateVariants:
+		return m.updateVariants(msg)
+	case stateCompare:
+		return m.updateCompare(msg)
+	case stateFork:
+		return m.updateFork(msg)
+	case stateStage:
+		return m.updateStage(msg)
+	case stateMeta:
+		return m.updateMeta(msg)
+	case stateFilter:
+		return m.updateFilter(msg)
+	case stateResume:
+		return m.updateResume(msg)
 	default:
 		return m.updateNormal(msg)
 	}
@@ -408,19 +895,129 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 func (m Model) updateNormal(msg tea.Msg) (tea.Model, tea.Cmd) {
 	switch msg := msg.(type) {
 	case tea.KeyMsg:
+		m.notice = ""
+		killArmed, restartArmed := m.killArmed, m.restartArmed
+		m.killArmed, m.restartArmed = "", ""
+		if m.triageNext != "" {
+			m.stopTriage()
+			m.setNotice("triage stopped", false)
+			return m, nil
+		}
+		if m.board {
+			switch msg.String() {
+			case "left", "h":
+				m.moveBoard(-1, 0)
+				return m, nil
+			case "right", "l":
+				m.moveBoard(1, 0)
+				return m, nil
+			case "up", "k":
+				m.moveBoard(0, -1)
+				return m, nil
+			case "down", "j":
+				m.moveBoard(0, 1)
+				return m, nil
+			}
+		}
 		switch msg.String() {
 		case "ctrl+c", "q":
 			return m, tea.Quit
 		case "r":
 			m.loading = true
-			return m, fetchSessions
+			return m, m.fetchSessions()
+		case "X":
+			s := m.selectedSession()
+			if s == nil || !s.TmuxRunning {
+				m.setNotice("no agent running", true)
+				return m, nil
+			}
+			if killArmed != s.Path {
+				m.killArmed = s.Path
+				m.setNotice("press X again to kill "+s.Slug+" and its windows", true)
+				return m, nil
+			}
+			return m, killSessionCmd(*s)
+		case "R":
+			s := m.selectedSession()
+			if s == nil {
+				return m, nil
+			}
+			// A live agent may be mid-task, so restarting it takes a second press.
+			if s.TmuxRunning && !s.AgentExited && restartArmed != s.Path {
+				m.restartArmed = s.Path
+				m.setNotice("press R again to restart "+s.Slug+"'s running agent", true)
+				return m, nil
+			}
+			m.setNotice("restarting "+s.Slug+"…", false)
+			return m, restartCmd(*s, m.agentOptions(*s))
+		case "U":
+			return m.restoreFleet()
+		case "H":
+			m.state = stateHelp
+			return m, nil
+		case "N":
+			return m.attachNext()
+		case "W":
+			return m.startTriage()
 		case "n":
-			m.state = stateNewSession
+			return m.resetNewSession()
+		case "I":
+			m.state = stateSpawnPath
 			m.inputErr = ""
-			m.nameInput.Placeholder = "e.g. phase-2-gitlab-mr-linking"
+			m.nameInput.Placeholder = "tasks.yaml"
 			m.nameInput.Reset()
 			m.nameInput.Focus()
 			return m, textinput.Blink
+		case "V":
+			return m.resetVariants()
+		case "v":
+			s := m.selectedSession()
+			if s == nil || s.Variant == "" {
+				m.setNotice("not a variant — V creates variants", true)
+				return m, nil
+			}
+			return m.openCompare(s)
+		case "?", "/":
+			return m.openFilter()
+		case "esc":
+			if m.filter.active() {
+				m.filter = sessionFilter{}
+				m.filterInput.Reset()
+				m.buildItems()
+			}
+			return m, nil
+		case "e":
+			if s := m.selectedSession(); s != nil {
+				return m.openMeta(s)
+			}
+			return m, nil
+		case "b":
+			m.board = !m.board
+			m.buildItems()
+			return m, nil
+		case "O":
+			m.cycleSort()
+			return m, nil
+		case "G":
+			m.cycleGroup()
+			return m, nil
+		case " ":
+			if r, ok := m.selectedRow(); ok && r.session < 0 {
+				m.toggleGroup(r)
+			}
+			return m, nil
+		case "s":
+			if m.selectedSession() != nil {
+				m.state = stateStage
+			}
+			return m, nil
+		case "F":
+			s := m.selectedSession()
+			if s == nil || s.Branch == "detached" {
+				m.setNotice("nothing to fork", true)
+				return m, nil
+			}
+			return m.openFork(s)
 		case "c":
 			s := m.selectedSession()
 			if s != nil {
@@ -436,52 +1033,85 @@ func (m Model) updateNormal(msg tea.Msg) (tea.Model, tea.Cmd) {
 				return m, openURLCmd(s.MR.WebURL)
 			}
 			return m, nil
+		case "p":
+			s := m.selectedSession()
+			if s != nil && s.MR != nil {
+				return m.openPipeline(s)
+			}
+			return m, nil
+		case "f":
+			s := m.selectedSession()
+			if s == nil || s.MR == nil || s.MR.PipelineStatus != "failed" {
+				m.setNotice("no failed pipeline to fix", true)
+				return m, nil
+			}
+			if m.store != nil {
+				// Manual fixes don't count against the auto-fix cap, but they
+				// stop auto-fix from sending a duplicate for this pipeline.
+				fix := m.store.CIFixes[s.Branch]
+				fix.LastPipeline = s.MR.PipelineID
+				fix.LastAt = time.Now()
+				m.store.CIFixes[s.Branch] = fix
+				if err := m.store.Save(); err != nil {
+					m.setNotice(err.Error(), true)
+				}
+			}
+			m.setNotice("fetching failing job logs for "+s.Slug+"…", false)
+			return m, fixCICmd(*s, m.agentOptions(*s), m.cfg.CI.LogTailLines)
+		case "t":
+			s := m.selectedSession()
+			if s != nil && s.MR != nil {
+				return m.openThreads(s)
+			}
+			return m, nil
+		case "a":
+			s := m.selectedSession()
+			if s == nil || s.MR == nil || !s.MR.HasUnresolved {
+				m.setNotice("no unresolved threads to address", true)
+				return m, nil
+			}
+			m.setNotice("fetching unresolved threads for "+s.Slug+"…", false)
+			return m, addressReviewCmd(*s, m.agentOptions(*s), nil)
 		case "d":
 			s := m.selectedSession()
 			if s != nil && s.Path != m.repoRoot {
 				m.state = stateDeleteConfirm
 				m.inputErr = ""
+				m.deleteCheck = nil
+				return m, checkDeleteCmd(*s)
+			}
+			return m, nil
+		case "T":
+			m.state = stateTrash
+			m.trashErr = ""
+			m.trashPurgeArmed = false
+			return m, nil
+		case "M":
+			if len(m.retireCandidates()) == 0 {
+				m.setNotice("no merged worktrees to retire", true)
 				return m, nil
 			}
+			m.state = stateRetireConfirm
 			return m, nil
-		case "enter":
+		case "1", "2", "3", "4", "5", "6", "7", "8", "9":
 			s := m.selectedSession()
-			if s != nil {
-				return m, ensureAndAttachCmd(*s)
+			if s == nil {
+				return m, nil
 			}
-			return m, nil
-		}
-	}
-	var cmd tea.Cmd
-	m.list, cmd = m.list.Update(msg)
-	return m, cmd
-}
-
-func (m Model) updateNewSession(msg tea.Msg) (tea.Model, tea.Cmd) {
-	switch msg := msg.(type) {
-	case tea.KeyMsg:
-		switch msg.String() {
-		case "esc":
-			m.state = stateNormal
-			m.inputErr = ""
-			m.nameInput.Blur()
-			return m, nil
+			return m.startSession(*s, int(msg.String()[0]-'0'))
 		case "enter":
-			branch := strings.TrimSpace(m.nameInput.Value())
-			if branch == "" {
-				m.inputErr = "branch name cannot be empty"
+			if r, ok := m.selectedRow(); ok && r.session < 0 {
+				m.toggleGroup(r)
 				return m, nil
 			}
-			if m.repoRoot == "" {
-				m.inputErr = "could not determine git repo root"
-				return m, nil
+			if s := m.selectedSession(); s != nil {
+				return m.startSession(*s, 0)
 			}
-			m.inputErr = ""
-			return m, createWorktreeCmd(m.repoRoot, branch)
+			return m, nil
 		}
 	}
 	var cmd tea.Cmd
-	m.nameInput, cmd = m.nameInput.Update(msg)
+	m.list, cmd = m.list.Update(msg)
 	return m, cmd
 }
 
@@ -553,7 +1183,45 @@ func (m Model) updateDeleteConfirm(msg tea.Msg) (tea.Model, tea.Cmd) {
 				m.state = stateNormal
 				return m, nil
 			}
-			return m, deleteWorktreeCmd(m.repoRoot, s.Path, s.Branch)
+			m.inputErr = ""
+			return m, archiveCmd(m.repoRoot, *s)
+		case "D":
+			s := m.selectedSession()
+			if s == nil {
+				m.state = stateNormal
+				return m, nil
+			}
+			if m.deleteCheck == nil {
+				m.inputErr = "still checking for unsaved work…"
+				return m, nil
+			}
+			// Anything that would be lost needs a second, explicit confirmation.
+			if m.deleteCheck.err != nil || !m.deleteCheck.work.Clean() {
+				m.state = stateDeleteForce
+				m.inputErr = ""
+				return m, nil
+			}
+			return m, deleteWorktreeCmd(m.repoRoot, *s, false)
+		}
+	}
+	return m, nil
+}
+
+func (m Model) updateDeleteForce(msg tea.Msg) (tea.Model, tea.Cmd) {
+	switch msg := msg.(type) {
+	case tea.KeyMsg:
+		switch msg.String() {
+		case "esc", "n", "N":
+			m.state = stateDeleteConfirm
+			m.inputErr = ""
+			return m, nil
+		case "F":
+			s := m.selectedSession()
+			if s == nil {
+				m.state = stateNormal
+				return m, nil
+			}
+			return m, deleteWorktreeCmd(m.repoRoot, *s, true)
 		}
 	}
 	return m, nil
@@ -574,7 +1242,21 @@ func (m Model) View() string {
 		)
 	}
 
+	switch m.state {
+	case statePipeline:
+		return m.renderPipeline()
+	case stateThreads:
+		return m.renderThreads()
+	case stateTrash:
+		return m.renderTrash()
+	case stateCompare:
+		return m.renderCompare()
+	}
+
 	body := lipgloss.JoinHorizontal(lipgloss.Top, m.list.View(), m.renderDetail())
+	if m.board {
+		body = m.renderBoard()
+	}
 	base := lipgloss.JoinVertical(lipgloss.Left, body, m.renderHelp())
 
 	switch m.state {
@@ -584,8 +1266,30 @@ func (m Model) View() string {
 		return m.renderCommitTypeModalOver(base)
 	case stateCommit:
 		return m.renderCommitModalOver(base)
-	case stateDeleteConfirm:
+	case stateDeleteConfirm, stateDeleteForce:
 		return m.renderDeleteConfirmOver(base)
+	case stateRetireConfirm:
+		return m.renderRetireConfirmOver(base)
+	case stateRetireSummary:
+		return m.renderRetireSummaryOver(base)
+	case stateHelp:
+		return m.renderHelpOver(base)
+	case stateSetup:
+		return m.renderSetupOver(base)
+	case stateSpawnPath:
+		return m.renderSpawnPathOver(base)
+	case stateSpawnReport:
+		return m.renderSpawnReportOver(base)
+	case stateVariants:
+		return m.renderVariantsOver(base)
+	case stateFork:
+		return m.renderForkOver(base)
+	case stateStage:
+		return m.renderStageOver(base)
+	case stateMeta:
+		return m.renderMetaOver(base)
+	case stateResume:
+		return m.renderResumeOver(base)
 	}
 	return base
 }
@@ -612,6 +1316,12 @@ func (m Model) renderDetail() string {
 	// Width of inner text area: box width minus padding
 	contentWidth := (dw - 1) - 3 - 2
 
+	if r, ok := m.selectedRow(); ok && r.session < 0 {
+		head := detailHeadStyle.Render(strings.ToUpper(r.group)) + "\n\n" +
+			labelStyle.Render("SESSIONS ") + fmt.Sprint(r.count) + "\n\n" +
+			dimStyle.Render("ENTER/SPACE  COLLAPSE OR EXPAND")
+		return style.Render(head)
+	}
 	s := m.selectedSession()
 	if s == nil {
 		return style.Render(dimStyle.Render("NO SESSIONS FOUND"))
@@ -623,6 +1333,10 @@ func (m Model) renderDetail() string {
 
 	var statusVal string
 	switch {
+	case s.Crashed():
+		statusVal = errStyle.Render(fmt.Sprintf("✕ CRASHED · EXIT %d", s.ExitStatus))
+	case s.AgentExited && !s.NeedsInput:
+		statusVal = dimStyle.Render("· FINISHED")
 	case s.NeedsInput:
 		statusVal = warnStyle.Render("▲ INPUT REQ")
 	case s.TmuxRunning:
@@ -635,19 +1349,75 @@ func (m Model) renderDetail() string {
 	b.WriteString(detailHeadStyle.Render(strings.ToUpper(s.Slug)) + "\n\n")
 	b.WriteString(row("BRANCH   ", s.Branch))
 	b.WriteString(row("PATH     ", s.Path))
+	if s.Base != "" {
+		b.WriteString(row("BASE     ", s.Base+dimStyle.Render(fmt.Sprintf("  ↑%d ↓%d", s.Ahead, s.Behind))))
+	}
 	b.WriteString(row("STATUS   ", statusVal))
+	stage := stageLabel(s.Stage)
+	if s.StageManual {
+		stage += dimStyle.Render("  set by hand · auto: " + string(model.DeriveStage(*s)))
+	}
+	b.WriteString(row("STAGE    ", stage))
+	if !s.Meta.CreatedAt.IsZero() {
+		b.WriteString(row("CREATED  ", s.Meta.CreatedAt.Format("2006-01-02 15:04")+dimStyle.Render("  "+ago(s.Meta.CreatedAt))))
+	}
+	if s.Meta.Agent != "" {
+		b.WriteString(row("AGENT    ", s.Meta.Agent))
+	}
+	if s.Meta.Priority > 0 {
+		b.WriteString(row("PRIORITY ", priorityLabel(s.Meta.Priority)))
+	}
+	if len(s.Meta.Tags) > 0 {
+		b.WriteString(row("TAGS     ", "#"+strings.Join(s.Meta.Tags, " #")))
+	}
+	if s.Parent != "" {
+		b.WriteString(row("PARENT   ", s.Parent))
+	}
+	if s.Variant != "" {
+		b.WriteString(row("VARIANT  ", s.Variant+dimStyle.Render(fmt.Sprintf("  %d competing · v compare", len(m.variantsOf(s.Variant))))))
+	}
+	if len(s.Ports) > 0 {
+		b.WriteString("\n" + sectionSep("PORTS", contentWidth) + "\n\n")
+		b.WriteString(renderPorts(s.Ports))
+	}
+	if len(s.Windows) > 1 {
+		b.WriteString("\n" + sectionSep("WINDOWS", contentWidth) + "\n\n")
+		for n, w := range s.Windows {
+			b.WriteString(labelStyle.Render(fmt.Sprintf("%d  ", n+1)) + fmt.Sprintf("%-10s ", w.Name) + windowStatus(w) + "\n")
+		}
+	}
 	b.WriteString("\n")
 	b.WriteString(sectionSep("MR", contentWidth) + "\n\n")
 
 	if s.MR != nil {
 		b.WriteString(renderMR(s.MR, contentWidth))
+		if s.MR.HasUnresolved {
+			b.WriteString("\n" + dimStyle.Render("t  VIEW THREADS   a  ADDRESS REVIEW") + "\n")
+		}
 	} else {
 		b.WriteString(dimStyle.Render("NO MR FOUND") + "\n")
 	}
 
+	if s.Meta.Notes != "" || s.Meta.Prompt != "" {
+		b.WriteString("\n" + sectionSep("NOTES", contentWidth) + "\n\n")
+		if s.Meta.Notes != "" {
+			b.WriteString(lipgloss.NewStyle().Width(contentWidth).Render(s.Meta.Notes) + "\n")
+		}
+		if s.Meta.Prompt != "" {
+			task := strings.ReplaceAll(s.Meta.Prompt, "\n", " ")
+			if limit := contentWidth - 9; limit > 0 && len([]rune(task)) > limit {
+				task = string([]rune(task)[:limit-1]) + "…"
+			}
+			b.WriteString(row("TASK     ", dimStyle.Render(task)))
+		}
+	}
+
 	b.WriteString("\n")
+	if s.Crashed() {
+		b.WriteString(dimStyle.Render("R  RELAUNCH, RESUMING THE CONVERSATION\n"))
+	}
 	if s.TmuxRunning {
-		b.WriteString(dimStyle.Render("CTRL+]  DETACH WITHOUT STOPPING CLAUDE\n"))
+		b.WriteString(dimStyle.Render(strings.ToUpper(tmux.KeyLabel(m.cfg.Tmux.DetachKey)) + "  DETACH WITHOUT STOPPING CLAUDE\n"))
 	}
 
 	return style.Render(b.String())
@@ -730,40 +1500,83 @@ func pipelineLabel(status string) string {
 	}
 }
 
+// windowStatus renders what a layout window is running.
+func windowStatus(w model.Window) string {
+	switch {
+	case !w.Dead:
+		return okStyle.Render("◆ " + w.Command)
+	case w.ExitStatus == 0:
+		return dimStyle.Render("· exited")
+	default:
+		return errStyle.Render(fmt.Sprintf("✕ exit %d", w.ExitStatus))
+	}
+}
+
 func (m Model) renderHelp() string {
 	var text string
 	switch m.state {
 	case stateNewSession:
-		text = "Enter create   Esc cancel"
+		text = "Enter/ctrl+s create & attach   ctrl+r create in background   Tab mode   ↑/↓ field/pick   Esc cancel"
 	case stateCommitType:
 		text = "key select type   Esc cancel"
 	case stateCommit:
 		text = "Enter commit   Esc ← type"
 	case stateDeleteConfirm:
-		text = "y/Enter confirm   n/Esc cancel"
+		text = "y/Enter archive   D delete permanently   n/Esc cancel"
+	case stateTrash:
+		text = "↑/↓ navigate   Enter/r restore   x purge   Esc back"
+	case stateSpawnPath:
+		text = "Enter import   Esc cancel"
+	case stateSpawnReport:
+		text = "any key close"
+	case stateFilter:
+		// The query being typed is shown at full strength, unlike help text.
+		sep := dimStyle.Render(strings.Repeat("─", m.width))
+		return sep + "\n" + lipgloss.NewStyle().PaddingLeft(2).Render(m.filterInput.View()+dimStyle.Render("   Enter keep   Esc clear"))
+	case stateMeta:
+		text = "Enter/ctrl+s save   Tab tags/notes   ctrl+p priority   ctrl+o pin   Esc cancel"
+	case stateStage:
+		text = "1-7 set stage   0 automatic   Esc cancel"
+	case stateResume:
+		text = "↑/↓ choose   Enter start   n new conversation   c continue most recent   Esc cancel"
+	case stateFork:
+		text = "Enter fork & attach   Tab resume conversation on/off   Esc cancel"
+	case stateVariants:
+		text = "ctrl+s/Enter create   Tab/shift+Tab field   Esc cancel"
+	case stateCompare:
+		text = "↑/↓ variant   t run tests   w pick winner (archives the rest)   f refresh   Esc back"
+	case stateSetup:
+		text = "running setup steps…"
+		if m.setupDone {
+			text = "Enter start claude   Esc back"
+		}
+	case stateDeleteForce:
+		text = "F force remove   n/Esc back"
+	case statePipeline:
+		text = "↑/↓ job   Enter log   PgUp/PgDn scroll   r retry job   x cancel job   R retry pipeline   X cancel pipeline   o open   f refresh   Esc back"
+	case stateRetireConfirm:
+		text = "y/Enter retire   n/Esc cancel"
+	case stateRetireSummary:
+		text = "any key close"
+	case stateThreads:
+		text = "↑/↓ thread   Space mark addressed   a send all to agent   R resolve marked   o open MR   f refresh   Esc back"
 	default:
-		text = "↑/↓ navigate   Enter attach   n new   c commit   o open MR   d delete   r refresh   q quit"
+		text = shortHelp
+		if m.board {
+			text = strings.Replace(text, "↑/↓ navigate", "←/→/↑/↓ navigate", 1)
+		}
+		if m.notice != "" {
+			if m.noticeErr {
+				text = errStyle.Render(m.notice)
+			} else {
+				text = okStyle.Render(m.notice)
+			}
+		}
 	}
 	sep := dimStyle.Render(strings.Repeat("─", m.width))
 	return sep + "\n" + helpStyle.Render(text)
 }
 
-func (m Model) renderModalOver(base string) string {
-	var b strings.Builder
-	b.WriteString(detailHeadStyle.Render("NEW SESSION") + "\n\n")
-	b.WriteString(labelStyle.Render("BRANCH NAME") + "\n")
-	b.WriteString(m.nameInput.View() + "\n")
-	if m.inputErr != "" {
-		b.WriteString("\n" + errStyle.Render(m.inputErr) + "\n")
-	}
-	b.WriteString("\n" + dimStyle.Render("creates .claude/worktrees/<slug> · opens claude"))
-
-	modal := modalStyle.Render(b.String())
-	return lipgloss.Place(m.width, m.height, lipgloss.Center, lipgloss.Center, modal,
-		lipgloss.WithWhitespaceBackground(lipgloss.Color("0")),
-	)
-}
-
 func (m Model) renderCommitTypeModalOver(base string) string {
 	s := m.selectedSession()
 	var b strings.Builder
@@ -824,11 +1637,21 @@ func (m Model) renderDeleteConfirmOver(base string) string {
 			b.WriteString(warnStyle.Render("▲ MR is still open") + "\n\n")
 		}
 	}
-	b.WriteString("This will run git worktree remove and delete the branch.\n")
+	b.WriteString(sectionSep("CHECKS", 52) + "\n\n")
+	b.WriteString(m.renderDeleteChecks())
+	b.WriteString("\nArchiving stops the tmux session and removes the worktree\n" +
+		"and branch, keeping the branch tip and uncommitted changes\n" +
+		"under " + git.TrashRefPrefix + " so T can restore them.\n")
 	if m.inputErr != "" {
 		b.WriteString("\n" + errStyle.Render(m.inputErr) + "\n")
 	}
-	b.WriteString("\n" + dimStyle.Render("y/Enter to confirm · Esc/n to cancel"))
+	if m.state == stateDeleteForce {
+		b.WriteString("\n" + errStyle.Render("▲ FORCE REMOVE — uncommitted changes and unpushed") + "\n" +
+			errStyle.Render("  commits above will be discarded.") + "\n")
+		b.WriteString("\n" + dimStyle.Render("F to force remove · Esc/n to go back"))
+	} else {
+		b.WriteString("\n" + dimStyle.Render("y/Enter to archive · D to delete permanently · Esc/n to cancel"))
+	}
 
 	modal := deleteModalStyle.Render(b.String())
 	return lipgloss.Place(m.width, m.height, lipgloss.Center, lipgloss.Center, modal,
@@ -836,13 +1659,50 @@ func (m Model) renderDeleteConfirmOver(base string) string {
 	)
 }
 
+// renderDeleteChecks lists each pre-delete risk with a pass/warn marker.
+func (m Model) renderDeleteChecks() string {
+	c := m.deleteCheck
+	if c == nil {
+		return dimStyle.Render("checking for unsaved work…") + "\n"
+	}
+	if c.err != nil {
+		return errStyle.Render("✕ "+c.err.Error()) + "\n"
+	}
+
+	check := func(n int, ok, risk string) string {
+		if n == 0 {
+			return okStyle.Render("◆ ") + ok + "\n"
+		}
+		return warnStyle.Render("▲ "+fmt.Sprintf(risk, n)) + "\n"
+	}
+
+	var b strings.Builder
+	b.WriteString(check(c.work.Uncommitted, "no uncommitted changes", "%d uncommitted file(s)"))
+	b.WriteString(check(c.work.Unpushed, "no unpushed commits", "%d unpushed commit(s)"))
+	b.WriteString(check(c.work.Stashes, "no stashes on this branch", "%d stash(es) on this branch — kept in the repo"))
+	switch {
+	case c.agentBusy:
+		b.WriteString(warnStyle.Render("▲ agent is working — it will be stopped") + "\n")
+	case c.agentRunning:
+		b.WriteString(dimStyle.Render("· agent idle — session will be closed") + "\n")
+	default:
+		b.WriteString(okStyle.Render("◆ ") + "no agent running\n")
+	}
+	return b.String()
+}
+
 func (m Model) selectedSession() *model.Session {
-	if len(m.sessions) == 0 {
+	r, ok := m.selectedRow()
+	if !ok || r.session < 0 || r.session >= len(m.sessions) {
 		return nil
 	}
-	idx := m.list.Index()
-	if idx < 0 || idx >= len(m.sessions) {
-		return nil
+	return &m.sessions[r.session]
+}
+
+// selectedPath returns the path of the selected session, or "" if none.
+func (m Model) selectedPath() string {
+	if s := m.selectedSession(); s != nil {
+		return s.Path
 	}
-	return &m.sessions[idx]
+	return ""
 }
@@ -0,0 +1,58 @@
+package tui
+
+import (
+	"testing"
+
+	"github.com/charmbracelet/bubbles/list"
+
+	"deckard/internal/model"
+)
+
+func testModel(t *testing.T, paths ...string) Model {
+	t.Helper()
+	t.Setenv("XDG_DATA_HOME", t.TempDir())
+	t.Setenv("CLAUDE_CONFIG_DIR", t.TempDir())
+	m := Model{list: list.New(nil, list.NewDefaultDelegate(), 80, 40)}
+	m.sessions = sessionsAt(paths...)
+	m.buildItems()
+	return m
+}
+
+func sessionsAt(paths ...string) []model.Session {
+	sessions := make([]model.Session, len(paths))
+	for i, p := range paths {
+		sessions[i] = model.Session{Path: p, Slug: p, Branch: p}
+	}
+	return sessions
+}
+
+// A refresh that returns fewer worktrees than are listed must not index the
+// new sessions with the old rows.
+func TestSessionsLoadedWithFewerSessions(t *testing.T) {
+	m := testModel(t, "/a", "/b", "/c")
+	m.list.Select(2)
+	if got := m.selectedPath(); got != "/c" {
+		t.Fatalf("selected %q before refresh, want /c", got)
+	}
+
+	next, _ := m.Update(sessionsLoadedMsg{sessions: sessionsAt("/a", "/b")})
+	m = next.(Model)
+	if len(m.rows) != 2 {
+		t.Fatalf("got %d rows, want 2", len(m.rows))
+	}
+	if s := m.selectedSession(); s == nil {
+		t.Fatal("no session selected after refresh")
+	}
+}
+
+// The selection follows its session when the list order changes.
+func TestSessionsLoadedKeepsSelection(t *testing.T) {
+	m := testModel(t, "/a", "/b", "/c")
+	m.list.Select(1)
+
+	next, _ := m.Update(sessionsLoadedMsg{sessions: sessionsAt("/c", "/a", "/b")})
+	m = next.(Model)
+	if got := m.selectedPath(); got != "/b" {
+		t.Errorf("selected %q after refresh, want /b", got)
+	}
+}
@@ -0,0 +1,98 @@
+package tui
+
+import (
+	"fmt"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+
+	"deckard/internal/gitlab"
+	"deckard/internal/model"
+	"deckard/internal/prompt"
+	"deckard/internal/tmux"
+)
+
+type ciFixSentMsg struct {
+	slug string
+	jobs int
+	err  error
+}
+
+// fixCICmd fetches the failing jobs of the session's pipeline, trims their
+// logs and delivers a "please fix" prompt to the session's agent, starting it
+// with opts if it is not running.
+func fixCICmd(s model.Session, opts tmux.Options, tailLines int) tea.Cmd {
+	return func() tea.Msg {
+		id := 0
+		if s.MR != nil {
+			id = s.MR.PipelineID
+		}
+		p, err := gitlab.FetchPipeline(s.Branch, id)
+		if err != nil {
+			return ciFixSentMsg{slug: s.Slug, err: err}
+		}
+
+		var logs []prompt.JobLog
+		for _, j := range p.Jobs {
+			if j.Status != "failed" || j.AllowFailure {
+				continue
+			}
+			raw, err := gitlab.FetchJobLog(j.ID)
+			if err != nil {
+				return ciFixSentMsg{slug: s.Slug, err: err}
+			}
+			logs = append(logs, prompt.JobLog{
+				Job:   j,
+				Lines: prompt.TailLines(gitlab.ParseJobLog(raw, true), tailLines),
+			})
+		}
+		if len(logs) == 0 {
+			return ciFixSentMsg{slug: s.Slug, err: fmt.Errorf("pipeline #%d has no failing jobs", p.ID)}
+		}
+
+		if err := tmux.Deliver(s.Slug, s.Path, prompt.CIFix(s.Branch, p, logs), opts); err != nil {
+			return ciFixSentMsg{slug: s.Slug, err: err}
+		}
+		return ciFixSentMsg{slug: s.Slug, jobs: len(logs)}
+	}
+}
+
+// autoFixCmds applies the auto-fix policy after a refresh: each newly failed
+// pipeline gets one fix prompt, up to the configured attempts per branch.
+// A passing pipeline resets the branch's attempt count.
+func (m *Model) autoFixCmds() []tea.Cmd {
+	if m.store == nil {
+		return nil
+	}
+	var cmds []tea.Cmd
+	dirty := false
+	for _, s := range m.sessions {
+		if s.MR == nil || s.MR.State != "opened" {
+			continue
+		}
+		fix, seen := m.store.CIFixes[s.Branch]
+		switch s.MR.PipelineStatus {
+		case "success":
+			if seen {
+				delete(m.store.CIFixes, s.Branch)
+				dirty = true
+			}
+		case "failed":
+			if !m.cfg.CI.AutoFix || fix.LastPipeline == s.MR.PipelineID || fix.Attempts >= m.cfg.CI.MaxRetries {
+				continue
+			}
+			fix.Attempts++
+			fix.LastPipeline = s.MR.PipelineID
+			fix.LastAt = time.Now()
+			m.store.CIFixes[s.Branch] = fix
+			dirty = true
+			cmds = append(cmds, fixCICmd(s, m.agentOptions(s), m.cfg.CI.LogTailLines))
+		}
+	}
+	if dirty {
+		if err := m.store.Save(); err != nil {
+			m.setNotice(err.Error(), true)
+		}
+	}
+	return cmds
+}
@@ -0,0 +1,184 @@
+package tui
+
+import (
+	"sort"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/sahilm/fuzzy"
+
+	"deckard/internal/model"
+)
+
+// sessionFilter narrows the session list. A query is a mix of structured
+// terms, which must all hold, and free text, fuzzy-matched against slug,
+// branch, MR title, tags and notes:
+//
+//	state:needs-input  stage of the session (prefix match)
+//	ci:failed          pipeline status of the MR; ci:none for no pipeline
+//	mr:merged          MR state (opened, merged, closed); mr:none for no MR
+//	tag:billing        tagged billing
+//	dirty              uncommitted changes
+//	pinned             pinned sessions
+type sessionFilter struct {
+	query string
+	terms []func(model.Session) bool
+	words []string
+}
+
+func parseFilter(query string) sessionFilter {
+	f := sessionFilter{query: strings.TrimSpace(query)}
+	for _, tok := range strings.Fields(f.query) {
+		if term := filterTerm(strings.ToLower(tok)); term != nil {
+			f.terms = append(f.terms, term)
+		} else {
+			f.words = append(f.words, tok)
+		}
+	}
+	return f
+}
+
+// filterTerm returns the predicate for a structured term, or nil if tok is
+// free text.
+func filterTerm(tok string) func(model.Session) bool {
+	switch tok {
+	case "dirty":
+		return func(s model.Session) bool { return s.Uncommitted > 0 }
+	case "pinned":
+		return func(s model.Session) bool { return s.Meta.Pinned }
+	}
+	key, val, ok := strings.Cut(tok, ":")
+	if !ok || val == "" {
+		return nil
+	}
+	switch key {
+	case "state", "stage":
+		return func(s model.Session) bool { return strings.HasPrefix(string(s.Stage), val) }
+	case "ci":
+		return func(s model.Session) bool {
+			status := ""
+			if s.MR != nil {
+				status = s.MR.PipelineStatus
+			}
+			if val == "none" {
+				return status == ""
+			}
+			return status != "" && strings.HasPrefix(status, val)
+		}
+	case "mr":
+		return func(s model.Session) bool {
+			if val == "none" {
+				return s.MR == nil
+			}
+			return s.MR != nil && strings.HasPrefix(s.MR.State, val)
+		}
+	case "tag":
+		return func(s model.Session) bool { return s.Meta.HasTag(val) }
+	}
+	return nil
+}
+
+func (f sessionFilter) active() bool { return f.query != "" }
+
+// apply returns the indexes of the sessions that pass the filter. Without
+// free text the order is kept; with it, best matches come first.
+func (f sessionFilter) apply(sessions []model.Session) []int {
+	var idxs []int
+	for i, s := range sessions {
+		if f.matchTerms(s) {
+			idxs = append(idxs, i)
+		}
+	}
+	if len(f.words) == 0 {
+		return idxs
+	}
+
+	haystacks := make([]string, len(idxs))
+	for j, i := range idxs {
+		haystacks[j] = searchText(sessions[i])
+	}
+	// Every word must match; scores add up.
+	score := make([]int, len(idxs))
+	hits := make([]int, len(idxs))
+	for _, w := range f.words {
+		for _, match := range fuzzy.Find(w, haystacks) {
+			score[match.Index] += match.Score
+			hits[match.Index]++
+		}
+	}
+	var matched []int
+	for j := range idxs {
+		if hits[j] == len(f.words) {
+			matched = append(matched, j)
+		}
+	}
+	sort.SliceStable(matched, func(a, b int) bool { return score[matched[a]] > score[matched[b]] })
+	out := make([]int, len(matched))
+	for k, j := range matched {
+		out[k] = idxs[j]
+	}
+	return out
+}
+
+func (f sessionFilter) matchTerms(s model.Session) bool {
+	for _, t := range f.terms {
+		if !t(s) {
+			return false
+		}
+	}
+	return true
+}
+
+// searchText is what free-text filtering matches against.
+func searchText(s model.Session) string {
+	parts := []string{s.Slug, s.Branch}
+	if s.MR != nil {
+		parts = append(parts, s.MR.Title)
+	}
+	for _, t := range s.Meta.Tags {
+		parts = append(parts, "#"+t)
+	}
+	if s.Meta.Notes != "" {
+		parts = append(parts, s.Meta.Notes)
+	}
+	return strings.Join(parts, " ")
+}
+
+// — filter input ————————————————————————————————————————————————————————————
+
+func (m Model) openFilter() (tea.Model, tea.Cmd) {
+	m.state = stateFilter
+	m.filterInput.SetValue(m.filter.query)
+	m.filterInput.CursorEnd()
+	return m, m.filterInput.Focus()
+}
+
+func (m Model) updateFilter(msg tea.Msg) (tea.Model, tea.Cmd) {
+	if key, ok := msg.(tea.KeyMsg); ok {
+		switch key.String() {
+		case "esc":
+			m.filterInput.Reset()
+			m.filterInput.Blur()
+			m.filter = sessionFilter{}
+			m.state = stateNormal
+			m.buildItems()
+			return m, nil
+		case "enter":
+			m.filterInput.Blur()
+			m.state = stateNormal
+			return m, nil
+		case "up", "down":
+			var cmd tea.Cmd
+			m.list, cmd = m.list.Update(msg)
+			return m, cmd
+		}
+	}
+	var cmd tea.Cmd
+	m.filterInput, cmd = m.filterInput.Update(msg)
+	if q := m.filterInput.Value(); q != m.filter.query {
+		m.filter = parseFilter(q)
+		m.buildItems()
+		m.list.Select(0)
+	}
+	return m, cmd
+}
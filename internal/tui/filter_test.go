@@ -0,0 +1,83 @@
+package tui
+
+import (
+	"slices"
+	"testing"
+
+	"deckard/internal/model"
+)
+
+func TestParseFilter(t *testing.T) {
+	f := parseFilter("  state:needs  Retry tag:billing dirty ci: payment ")
+	if f.query != "state:needs  Retry tag:billing dirty ci: payment" {
+		t.Errorf("query = %q", f.query)
+	}
+	if len(f.terms) != 3 {
+		t.Errorf("got %d terms, want 3", len(f.terms))
+	}
+	// A key with no value is free text, and words keep their case.
+	if want := []string{"Retry", "ci:", "payment"}; !slices.Equal(f.words, want) {
+		t.Errorf("words = %q, want %q", f.words, want)
+	}
+	if parseFilter("   ").active() {
+		t.Error("blank filter is active")
+	}
+}
+
+func TestFilterTerms(t *testing.T) {
+	failing := model.Session{Stage: model.StageCIFailing, MR: &model.MR{State: "opened", PipelineStatus: "failed"}}
+	merged := model.Session{Stage: model.StageMerged, MR: &model.MR{State: "merged"}}
+	bare := model.Session{Stage: model.StageWorking, Uncommitted: 2, Meta: model.Meta{Tags: []string{"Billing"}, Pinned: true}}
+
+	tests := []struct {
+		query string
+		want  []bool // failing, merged, bare
+	}{
+		{"state:ci", []bool{true, false, false}},
+		{"STAGE:merged", []bool{false, true, false}},
+		{"ci:fail", []bool{true, false, false}},
+		{"ci:none", []bool{false, true, true}},
+		{"mr:opened", []bool{true, false, false}},
+		{"mr:none", []bool{false, false, true}},
+		{"tag:billing", []bool{false, false, true}},
+		{"tag:Billing", []bool{false, false, true}},
+		{"dirty", []bool{false, false, true}},
+		{"pinned", []bool{false, false, true}},
+		{"mr:none dirty", []bool{false, false, true}},
+		{"mr:none ci:failed", []bool{false, false, false}},
+	}
+	for _, tt := range tests {
+		f := parseFilter(tt.query)
+		for i, s := range []model.Session{failing, merged, bare} {
+			if got := f.matchTerms(s); got != tt.want[i] {
+				t.Errorf("%q on session %d = %v, want %v", tt.query, i, got, tt.want[i])
+			}
+		}
+	}
+}
+
+func TestFilterApply(t *testing.T) {
+	sessions := []model.Session{
+		{Slug: "payment-retries", Stage: model.StageWorking},
+		{Slug: "login-page", Stage: model.StageWorking, Meta: model.Meta{Notes: "payment form too"}},
+		{Slug: "payments", Stage: model.StageMerged},
+		{Slug: "docs", Stage: model.StageWorking},
+	}
+
+	// Terms alone keep the list order.
+	if got := parseFilter("state:working").apply(sessions); !slices.Equal(got, []int{0, 1, 3}) {
+		t.Errorf("state:working = %v", got)
+	}
+	// Free text drops non-matches and ranks the rest.
+	got := parseFilter("payment").apply(sessions)
+	if len(got) != 3 || slices.Contains(got, 3) {
+		t.Errorf("payment = %v, want sessions 0-2", got)
+	}
+	// Every word has to match.
+	if got := parseFilter("payment login").apply(sessions); !slices.Equal(got, []int{1}) {
+		t.Errorf("payment login = %v, want [1]", got)
+	}
+	if got := parseFilter("payment state:merged").apply(sessions); !slices.Equal(got, []int{2}) {
+		t.Errorf("payment state:merged = %v, want [2]", got)
+	}
+}
@@ -0,0 +1,137 @@
+package tui
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+
+	"deckard/internal/claude"
+	"deckard/internal/git"
+	"deckard/internal/model"
+)
+
+type forkedMsg struct {
+	session  model.Session
+	resumeID string // conversation copied into the fork; empty for a fresh one
+	err      error
+}
+
+// forkCmd copies the worktree of parent, uncommitted changes included, to a
+// new branch. With resume, parent's latest conversation is copied along so
+// the fork's agent can pick it up.
+func forkCmd(repoRoot string, parent model.Session, branch string, resume bool) tea.Cmd {
+	return func() tea.Msg {
+		path, err := git.ForkWorktree(repoRoot, parent.Path, parent.Branch, branch)
+		s := model.Session{Slug: git.BranchToSlug(branch), Path: path, Branch: branch}
+		if err != nil {
+			return forkedMsg{session: s, err: err}
+		}
+		if !resume {
+			return forkedMsg{session: s}
+		}
+		id, err := claude.LatestConversation(parent.Path)
+		if err != nil || id == "" {
+			return forkedMsg{session: s, err: err}
+		}
+		if err := claude.CopyConversation(parent.Path, path, id); err != nil {
+			return forkedMsg{session: s, err: err}
+		}
+		return forkedMsg{session: s, resumeID: id}
+	}
+}
+
+func (m Model) handleForkMsg(msg tea.Msg) (Model, tea.Cmd, bool) {
+	fm, ok := msg.(forkedMsg)
+	if !ok {
+		return m, nil, false
+	}
+	if fm.err != nil && fm.session.Path == "" {
+		m.inputErr = fm.err.Error()
+		return m, nil, true
+	}
+	m.inputErr = ""
+	m.nameInput.Reset()
+	m.nameInput.Blur()
+	if fm.err != nil {
+		// The worktree exists; carry on and report what went wrong after.
+		m.setNotice("fork: "+fm.err.Error(), true)
+	}
+	parent := m.forkParent.Meta
+	m.rememberNew(fm.session.Path, model.Meta{
+		Tags:      parent.Tags,
+		Priority:  parent.Priority,
+		CreatedAt: time.Now(),
+		Agent:     m.cfg.DefaultAgent,
+		Prompt:    parent.Prompt,
+	})
+	m.pendingLaunch = launch{attach: true}
+	if fm.resumeID != "" {
+		m.pendingLaunch.args = []string{"--resume", fm.resumeID}
+	}
+	sm, cmd := m.startSetup(fm.session)
+	return sm, cmd, true
+}
+
+// openFork prepares the fork modal for s.
+func (m Model) openFork(s *model.Session) (tea.Model, tea.Cmd) {
+	m.state = stateFork
+	m.forkParent = *s
+	m.forkResume = true
+	m.inputErr = ""
+	m.nameInput.Placeholder = s.Branch + "-fork"
+	m.nameInput.Reset()
+	return m, m.nameInput.Focus()
+}
+
+func (m Model) updateFork(msg tea.Msg) (tea.Model, tea.Cmd) {
+	if key, ok := msg.(tea.KeyMsg); ok {
+		switch key.String() {
+		case "esc":
+			m.state = stateNormal
+			m.inputErr = ""
+			m.nameInput.Blur()
+			return m, nil
+		case "tab":
+			m.forkResume = !m.forkResume
+			return m, nil
+		case "enter":
+			branch := strings.TrimSpace(m.nameInput.Value())
+			if branch == "" {
+				branch = m.nameInput.Placeholder
+			}
+			if git.BranchExists(m.repoRoot, branch) {
+				m.inputErr = fmt.Sprintf("branch %s already exists", branch)
+				return m, nil
+			}
+			m.inputErr = ""
+			return m, forkCmd(m.repoRoot, m.forkParent, branch, m.forkResume)
+		}
+	}
+	var cmd tea.Cmd
+	m.nameInput, cmd = m.nameInput.Update(msg)
+	return m, cmd
+}
+
+func (m Model) renderForkOver(base string) string {
+	var b strings.Builder
+	b.WriteString(detailHeadStyle.Render("FORK SESSION") + "  " + dimStyle.Render(strings.ToUpper(m.forkParent.Slug)) + "\n\n")
+	b.WriteString(labelStyle.Render("NEW BRANCH") + "\n")
+	b.WriteString(m.nameInput.View() + "\n\n")
+	if m.forkResume {
+		b.WriteString(okStyle.Render("◆") + " resume a copy of the conversation\n")
+	} else {
+		b.WriteString(dimStyle.Render("· start a fresh conversation") + "\n")
+	}
+	if m.inputErr != "" {
+		b.WriteString("\n" + errStyle.Render(m.inputErr) + "\n")
+	}
+	b.WriteString("\n" + dimStyle.Render("copies the worktree as it is now, uncommitted changes included · "+m.forkParent.Slug+" is not touched"))
+
+	modal := modalStyle.Render(b.String())
+	return lipgloss.Place(m.width, m.height, lipgloss.Center, lipgloss.Center, modal,
+		lipgloss.WithWhitespaceBackground(lipgloss.Color("0")),
+	)
+}
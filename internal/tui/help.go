@@ -0,0 +1,93 @@
+package tui
+
+import (
+	"fmt"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+// shortHelp is the help line under the session list; H opens keyHelp.
+const shortHelp = "↑/↓ navigate   Enter attach   n new   ? filter   d delete   r refresh   H all keys   q quit"
+
+type keyHint struct {
+	key  string
+	desc string
+}
+
+// keyHelp lists every key of the session list, by what it acts on.
+var keyHelp = []struct {
+	title string
+	keys  []keyHint
+}{
+	{"SESSIONS", []keyHint{
+		{"Enter", "attach to the agent"},
+		{"1-9", "attach to a layout window"},
+		{"N", "attach to the next waiting session"},
+		{"W", "triage waiting sessions one by one"},
+		{"X", "kill the session (press twice)"},
+		{"R", "restart the agent, resuming its conversation"},
+		{"U", "restore sessions lost with the tmux server"},
+	}},
+	{"WORKTREES", []keyHint{
+		{"n", "new worktree"},
+		{"I", "import tasks from a file"},
+		{"V", "start competing variants"},
+		{"v", "compare variants"},
+		{"F", "fork the worktree"},
+		{"c", "commit"},
+		{"d", "delete (archive to trash)"},
+		{"T", "trash"},
+		{"M", "retire merged worktrees"},
+	}},
+	{"MERGE REQUESTS", []keyHint{
+		{"o", "open the MR"},
+		{"p", "pipeline"},
+		{"f", "send failing CI logs to the agent"},
+		{"t", "review threads"},
+		{"a", "send review threads to the agent"},
+	}},
+	{"LIST", []keyHint{
+		{"? /", "filter"},
+		{"O", "cycle sort order"},
+		{"G", "cycle grouping"},
+		{"Space", "collapse or expand a group"},
+		{"b", "board view"},
+		{"s", "set stage"},
+		{"e", "edit notes and tags"},
+		{"r", "refresh"},
+		{"q", "quit"},
+	}},
+}
+
+func (m Model) updateHelp(msg tea.Msg) (tea.Model, tea.Cmd) {
+	if _, ok := msg.(tea.KeyMsg); ok {
+		m.state = stateNormal
+	}
+	return m, nil
+}
+
+func (m Model) renderHelpOver(base string) string {
+	// Two columns keep the overlay short enough for a small terminal.
+	var cols [2]strings.Builder
+	for i, section := range keyHelp {
+		b := &cols[i%2]
+		if b.Len() > 0 {
+			b.WriteString("\n")
+		}
+		b.WriteString(labelStyle.Render(section.title) + "\n")
+		for _, k := range section.keys {
+			b.WriteString(fmt.Sprintf("  %s  %s\n", okStyle.Render(fmt.Sprintf("%-6s", k.key)), k.desc))
+		}
+	}
+	body := lipgloss.JoinHorizontal(lipgloss.Top,
+		lipgloss.NewStyle().PaddingRight(4).Render(cols[0].String()),
+		cols[1].String(),
+	)
+
+	modal := modalStyle.Render(detailHeadStyle.Render("KEYS") + "\n\n" + body + "\n" + dimStyle.Render("any key to close"))
+	return lipgloss.Place(m.width, m.height, lipgloss.Center, lipgloss.Center, modal,
+		lipgloss.WithWhitespaceBackground(lipgloss.Color("0")),
+	)
+}
@@ -0,0 +1,301 @@
+package tui
+
+import (
+	"fmt"
+	"slices"
+	"sort"
+	"strings"
+
+	"github.com/charmbracelet/bubbles/list"
+
+	"deckard/internal/model"
+	"deckard/internal/store"
+)
+
+// Sort orders of the session list. Pinned sessions always come first.
+const (
+	sortAttention = "attention" // most in need of a human first
+	sortActivity  = "activity"  // most recently active first
+	sortCreated   = "created"   // newest first
+	sortName      = "name"
+	sortMR        = "mr" // open MRs, then no MR, merged and closed
+)
+
+var sortModes = []string{sortAttention, sortActivity, sortCreated, sortName, sortMR}
+
+// Groupings of the session list.
+const (
+	groupNone   = ""
+	groupStage  = "stage"
+	groupTag    = "tag"    // by first tag
+	groupParent = "parent" // by the branch a session is stacked on or forked from
+)
+
+var groupModes = []string{groupNone, groupStage, groupTag, groupParent}
+
+// listRow is one entry of the session list: a session or a group header.
+type listRow struct {
+	session int    // index into Model.sessions; -1 for a header
+	group   string // group key, for headers
+	count   int    // sessions in the group, for headers
+}
+
+// headerItem is a group header in the session list.
+type headerItem struct {
+	label     string
+	count     int
+	collapsed bool
+}
+
+func (h headerItem) Title() string {
+	arrow := "▾"
+	if h.collapsed {
+		arrow = "▸"
+	}
+	return fmt.Sprintf("%s %s (%d)", arrow, strings.ToUpper(h.label), h.count)
+}
+
+func (h headerItem) Description() string { return "" }
+func (h headerItem) FilterValue() string { return h.label }
+
+// attentionRank orders stages by how urgently they need a human.
+var attentionRank = map[model.Stage]int{
+	model.StageNeedsInput: 0,
+	model.StageCIFailing:  1,
+	model.StageReady:      2,
+	model.StageInReview:   3,
+	model.StageWorking:    4,
+	model.StagePlanning:   5,
+	model.StageMerged:     6,
+}
+
+func mrRank(s model.Session) int {
+	switch {
+	case s.MR == nil:
+		return 1
+	case s.MR.State == "opened":
+		return 0
+	case s.MR.State == "merged":
+		return 2
+	default:
+		return 3
+	}
+}
+
+// sortSessions orders idxs (indexes into sessions) by mode.
+func sortSessions(sessions []model.Session, idxs []int, mode string) {
+	less := func(a, b model.Session) bool {
+		switch mode {
+		case sortActivity:
+			return a.LastActive.After(b.LastActive)
+		case sortCreated:
+			return a.Meta.CreatedAt.After(b.Meta.CreatedAt)
+		case sortName:
+			return a.Slug < b.Slug
+		case sortMR:
+			return mrRank(a) < mrRank(b)
+		default:
+			if attentionRank[a.Stage] != attentionRank[b.Stage] {
+				return attentionRank[a.Stage] < attentionRank[b.Stage]
+			}
+			// Explicit priorities break ties; unset sorts after P3.
+			pa, pb := a.Meta.Priority, b.Meta.Priority
+			if pa == 0 {
+				pa = 4
+			}
+			if pb == 0 {
+				pb = 4
+			}
+			return pa < pb
+		}
+	}
+	sort.SliceStable(idxs, func(i, j int) bool {
+		a, b := sessions[idxs[i]], sessions[idxs[j]]
+		if a.Meta.Pinned != b.Meta.Pinned {
+			return a.Meta.Pinned
+		}
+		return less(a, b)
+	})
+}
+
+// keepVariantsTogether moves the members of each variant group in idxs next
+// to the first of them.
+func keepVariantsTogether(sessions []model.Session, idxs []int) []int {
+	out := make([]int, 0, len(idxs))
+	placed := map[string]bool{}
+	for _, i := range idxs {
+		v := sessions[i].Variant
+		if v == "" {
+			out = append(out, i)
+			continue
+		}
+		if placed[v] {
+			continue
+		}
+		placed[v] = true
+		for _, j := range idxs {
+			if sessions[j].Variant == v {
+				out = append(out, j)
+			}
+		}
+	}
+	return out
+}
+
+// groupKey returns the group s falls in under mode.
+func groupKey(s model.Session, mode string) string {
+	switch mode {
+	case groupStage:
+		return string(s.Stage)
+	case groupTag:
+		if len(s.Meta.Tags) == 0 {
+			return "untagged"
+		}
+		return "#" + s.Meta.Tags[0]
+	case groupParent:
+		if s.Parent != "" {
+			return s.Parent
+		}
+		if s.Base != "" && !strings.HasPrefix(s.Base, "origin/") {
+			return s.Base
+		}
+		return "not stacked"
+	}
+	return ""
+}
+
+// layoutRows works out the list rows: filtered, then sorted (unless free
+// text ranks the matches), then grouped under headers. The board shows every
+// session, so it is never grouped.
+func (m Model) layoutRows() []listRow {
+	idxs := m.filter.apply(m.sessions)
+	if len(m.filter.words) == 0 {
+		sortSessions(m.sessions, idxs, m.listPrefs().Sort)
+	}
+	idxs = keepVariantsTogether(m.sessions, idxs)
+
+	mode := m.listPrefs().Group
+	if mode == groupNone || m.board {
+		rows := make([]listRow, len(idxs))
+		for k, i := range idxs {
+			rows[k] = listRow{session: i}
+		}
+		return rows
+	}
+
+	// Groups appear in the order of their first member.
+	var keys []string
+	members := map[string][]int{}
+	for _, i := range idxs {
+		k := groupKey(m.sessions[i], mode)
+		if _, ok := members[k]; !ok {
+			keys = append(keys, k)
+		}
+		members[k] = append(members[k], i)
+	}
+	var rows []listRow
+	for _, k := range keys {
+		rows = append(rows, listRow{session: -1, group: k, count: len(members[k])})
+		if m.collapsed(k) {
+			continue
+		}
+		for _, i := range members[k] {
+			rows = append(rows, listRow{session: i})
+		}
+	}
+	return rows
+}
+
+// — list preferences ————————————————————————————————————————————————————————
+
+// listPrefs returns the list preferences; without a store they only last
+// until deckard exits.
+func (m Model) listPrefs() store.ListPrefs {
+	if m.store == nil {
+		return m.prefs
+	}
+	return m.store.List
+}
+
+func (m *Model) setListPrefs(p store.ListPrefs) {
+	m.prefs = p
+	if m.store == nil {
+		return
+	}
+	m.store.List = p
+	if err := m.store.Save(); err != nil {
+		m.setNotice(err.Error(), true)
+	}
+}
+
+func collapseKey(mode, group string) string { return mode + "/" + group }
+
+func (m Model) collapsed(group string) bool {
+	p := m.listPrefs()
+	return slices.Contains(p.Collapsed, collapseKey(p.Group, group))
+}
+
+// toggleGroup collapses or expands the group of the header at row.
+func (m *Model) toggleGroup(row listRow) {
+	p := m.listPrefs()
+	key := collapseKey(p.Group, row.group)
+	if i := slices.Index(p.Collapsed, key); i >= 0 {
+		p.Collapsed = slices.Delete(slices.Clone(p.Collapsed), i, i+1)
+	} else {
+		p.Collapsed = append(slices.Clone(p.Collapsed), key)
+	}
+	m.setListPrefs(p)
+	m.buildItems()
+}
+
+// cycleSort switches to the next sort order.
+func (m *Model) cycleSort() {
+	p := m.listPrefs()
+	p.Sort = sortModes[(slices.Index(sortModes, sortOrDefault(p.Sort))+1)%len(sortModes)]
+	m.setListPrefs(p)
+	m.buildItems()
+	m.setNotice("sorted by "+p.Sort, false)
+}
+
+// cycleGroup switches to the next grouping.
+func (m *Model) cycleGroup() {
+	p := m.listPrefs()
+	p.Group = groupModes[(slices.Index(groupModes, p.Group)+1)%len(groupModes)]
+	m.setListPrefs(p)
+	m.buildItems()
+	if p.Group == groupNone {
+		m.setNotice("not grouped", false)
+	} else {
+		m.setNotice("grouped by "+p.Group, false)
+	}
+}
+
+func sortOrDefault(s string) string {
+	if s == "" {
+		return sortAttention
+	}
+	return s
+}
+
+// selectedRow returns the list row under the cursor, if any.
+func (m Model) selectedRow() (listRow, bool) {
+	idx := m.list.Index()
+	if idx < 0 || idx >= len(m.rows) {
+		return listRow{}, false
+	}
+	return m.rows[idx], true
+}
+
+// listItems turns rows into list items.
+func (m Model) listItems() []list.Item {
+	char := spinnerFrames[m.spinnerFrame]
+	items := make([]list.Item, len(m.rows))
+	for pos, r := range m.rows {
+		if r.session < 0 {
+			items[pos] = headerItem{label: r.group, count: r.count, collapsed: m.collapsed(r.group)}
+		} else {
+			items[pos] = sessionItem{s: m.sessions[r.session], spinnerChar: char}
+		}
+	}
+	return items
+}
@@ -0,0 +1,112 @@
+package tui
+
+import (
+	"slices"
+	"testing"
+	"time"
+
+	"deckard/internal/model"
+	"deckard/internal/store"
+)
+
+func slugs(sessions []model.Session, idxs []int) []string {
+	out := make([]string, len(idxs))
+	for k, i := range idxs {
+		out[k] = sessions[i].Slug
+	}
+	return out
+}
+
+func TestSortSessions(t *testing.T) {
+	now := time.Now()
+	sessions := []model.Session{
+		{Slug: "working", Stage: model.StageWorking, LastActive: now.Add(-time.Hour)},
+		{Slug: "p1", Stage: model.StageWorking, Meta: model.Meta{Priority: 1}, LastActive: now.Add(-2 * time.Hour)},
+		{Slug: "waiting", Stage: model.StageNeedsInput, LastActive: now.Add(-3 * time.Hour)},
+		{Slug: "pinned", Stage: model.StageMerged, Meta: model.Meta{Pinned: true}, LastActive: now.Add(-4 * time.Hour)},
+		{Slug: "merged", Stage: model.StageMerged, MR: &model.MR{State: "merged"}, LastActive: now},
+		{Slug: "review", Stage: model.StageInReview, MR: &model.MR{State: "opened"}, LastActive: now.Add(-5 * time.Hour)},
+	}
+
+	tests := []struct {
+		mode string
+		want []string
+	}{
+		{sortAttention, []string{"pinned", "waiting", "review", "p1", "working", "merged"}},
+		{sortActivity, []string{"pinned", "merged", "working", "p1", "waiting", "review"}},
+		{sortName, []string{"pinned", "merged", "p1", "review", "waiting", "working"}},
+		{sortMR, []string{"pinned", "review", "working", "p1", "waiting", "merged"}},
+	}
+	for _, tt := range tests {
+		idxs := []int{0, 1, 2, 3, 4, 5}
+		sortSessions(sessions, idxs, tt.mode)
+		if got := slugs(sessions, idxs); !slices.Equal(got, tt.want) {
+			t.Errorf("%s: got %v, want %v", tt.mode, got, tt.want)
+		}
+	}
+}
+
+func TestKeepVariantsTogether(t *testing.T) {
+	sessions := []model.Session{
+		{Slug: "a1", Variant: "a"},
+		{Slug: "x"},
+		{Slug: "a2", Variant: "a"},
+		{Slug: "y"},
+	}
+	got := slugs(sessions, keepVariantsTogether(sessions, []int{1, 2, 3, 0}))
+	if want := []string{"x", "a2", "a1", "y"}; !slices.Equal(got, want) {
+		t.Errorf("got %v, want %v", got, want)
+	}
+}
+
+func TestGroupKey(t *testing.T) {
+	tests := []struct {
+		s    model.Session
+		mode string
+		want string
+	}{
+		{model.Session{Stage: model.StageWorking}, groupStage, "working"},
+		{model.Session{Meta: model.Meta{Tags: []string{"api", "ui"}}}, groupTag, "#api"},
+		{model.Session{}, groupTag, "untagged"},
+		{model.Session{Parent: "feature", Base: "main"}, groupParent, "feature"},
+		{model.Session{Base: "feature"}, groupParent, "feature"},
+		{model.Session{Base: "origin/main"}, groupParent, "not stacked"},
+		{model.Session{Stage: model.StageWorking}, groupNone, ""},
+	}
+	for _, tt := range tests {
+		if got := groupKey(tt.s, tt.mode); got != tt.want {
+			t.Errorf("groupKey(%+v, %q) = %q, want %q", tt.s, tt.mode, got, tt.want)
+		}
+	}
+}
+
+func TestLayoutRowsGrouped(t *testing.T) {
+	m := Model{
+		sessions: []model.Session{
+			{Slug: "w1", Stage: model.StageWorking},
+			{Slug: "n1", Stage: model.StageNeedsInput},
+			{Slug: "w2", Stage: model.StageWorking},
+			{Slug: "m1", Stage: model.StageMerged},
+		},
+		prefs: store.ListPrefs{Group: groupStage, Collapsed: []string{collapseKey(groupStage, "merged")}},
+	}
+
+	var got []string
+	for _, r := range m.layoutRows() {
+		if r.session < 0 {
+			got = append(got, "["+r.group+"]")
+		} else {
+			got = append(got, m.sessions[r.session].Slug)
+		}
+	}
+	// Groups follow the sort order; the collapsed one keeps only its header.
+	want := []string{"[needs-input]", "n1", "[working]", "w1", "w2", "[merged]"}
+	if !slices.Equal(got, want) {
+		t.Errorf("got %v, want %v", got, want)
+	}
+
+	m.board = true
+	if rows := m.layoutRows(); len(rows) != 4 {
+		t.Errorf("board has %d rows, want 4 ungrouped", len(rows))
+	}
+}
@@ -0,0 +1,229 @@
+package tui
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+
+	"deckard/internal/model"
+)
+
+// metaField is the focused input of the metadata editor.
+type metaField int
+
+const (
+	metaTags metaField = iota
+	metaNotes
+)
+
+// applyMeta attaches the stored metadata to every session. Worktrees seen
+// for the first time get their creation time from the worktree's .git file;
+// entries whose worktree no longer exists are dropped.
+func (m *Model) applyMeta() {
+	if m.store == nil {
+		return
+	}
+	live := map[string]bool{}
+	changed := false
+	for i := range m.sessions {
+		s := &m.sessions[i]
+		live[s.Path] = true
+		meta, ok := m.store.Meta[s.Path]
+		if !ok && s.Path != m.repoRoot {
+			if info, err := os.Stat(filepath.Join(s.Path, ".git")); err == nil && !info.IsDir() {
+				meta.CreatedAt = info.ModTime()
+			}
+			m.store.Meta[s.Path] = meta
+			changed = true
+		}
+		s.Meta = meta
+	}
+	for path := range m.store.Meta {
+		if !live[path] && worktreeGone(path) {
+			delete(m.store.Meta, path)
+			changed = true
+		}
+	}
+	if changed {
+		if err := m.store.Save(); err != nil {
+			m.setNotice(err.Error(), true)
+		}
+	}
+}
+
+// worktreeGone reports whether the worktree at path no longer exists. A path
+// missing from the listing isn't enough: the store is read after the
+// worktrees are listed, so it can hold a worktree `deckard spawn` created in
+// between.
+func worktreeGone(path string) bool {
+	_, err := os.Stat(path)
+	return errors.Is(err, os.ErrNotExist)
+}
+
+// rememberNew records the metadata of a worktree deckard has just created.
+func (m *Model) rememberNew(path string, meta model.Meta) {
+	if m.store == nil || path == "" {
+		return
+	}
+	m.store.Remember(path, meta)
+	if err := m.store.Save(); err != nil {
+		m.setNotice(err.Error(), true)
+	}
+}
+
+// saveMeta replaces the metadata of the session at path.
+func (m *Model) saveMeta(path string, meta model.Meta) {
+	if m.store == nil {
+		m.setNotice("metadata needs the data dir", true)
+		return
+	}
+	m.store.Meta[path] = meta
+	if err := m.store.Save(); err != nil {
+		m.setNotice(err.Error(), true)
+	}
+	for i := range m.sessions {
+		if m.sessions[i].Path == path {
+			m.sessions[i].Meta = meta
+		}
+	}
+	m.buildItems()
+}
+
+// parseTags splits a comma- or space-separated tag list, dropping
+// duplicates and any leading #.
+func parseTags(s string) []string {
+	var tags []string
+	seen := map[string]bool{}
+	for _, t := range strings.FieldsFunc(s, func(r rune) bool { return r == ',' || r == ' ' }) {
+		t = strings.TrimPrefix(t, "#")
+		if t != "" && !seen[t] {
+			seen[t] = true
+			tags = append(tags, t)
+		}
+	}
+	return tags
+}
+
+func priorityLabel(p int) string {
+	switch p {
+	case 1:
+		return errStyle.Render("P1 HIGH")
+	case 2:
+		return warnStyle.Render("P2 MEDIUM")
+	case 3:
+		return dimStyle.Render("P3 LOW")
+	default:
+		return dimStyle.Render("─")
+	}
+}
+
+// ago renders how long ago t was, coarsely.
+func ago(t time.Time) string {
+	d := time.Since(t)
+	switch {
+	case d < time.Minute:
+		return "just now"
+	case d < time.Hour:
+		return fmt.Sprintf("%dm ago", int(d.Minutes()))
+	case d < 48*time.Hour:
+		return fmt.Sprintf("%dh ago", int(d.Hours()))
+	default:
+		return fmt.Sprintf("%dd ago", int(d.Hours()/24))
+	}
+}
+
+// — metadata editor —————————————————————————————————————————————————————————
+
+// openMeta prepares the metadata editor for s.
+func (m Model) openMeta(s *model.Session) (tea.Model, tea.Cmd) {
+	m.state = stateMeta
+	m.metaPath = s.Path
+	m.metaEdit = s.Meta
+	m.inputErr = ""
+	m.nameInput.Placeholder = "e.g. billing, spike"
+	m.nameInput.SetValue(strings.Join(s.Meta.Tags, ", "))
+	m.taskInput.Reset()
+	m.taskInput.SetValue(s.Meta.Notes)
+	return m, m.focusMetaField(metaTags)
+}
+
+func (m *Model) focusMetaField(f metaField) tea.Cmd {
+	m.metaField = f
+	m.nameInput.Blur()
+	m.taskInput.Blur()
+	if f == metaNotes {
+		return m.taskInput.Focus()
+	}
+	return m.nameInput.Focus()
+}
+
+func (m Model) updateMeta(msg tea.Msg) (tea.Model, tea.Cmd) {
+	if key, ok := msg.(tea.KeyMsg); ok {
+		switch key.String() {
+		case "esc":
+			m.state = stateNormal
+			m.nameInput.Blur()
+			m.taskInput.Blur()
+			return m, nil
+		case "tab", "shift+tab":
+			return m, m.focusMetaField(1 - m.metaField)
+		case "ctrl+p":
+			m.metaEdit.Priority = (m.metaEdit.Priority + 1) % 4
+			return m, nil
+		case "ctrl+o":
+			m.metaEdit.Pinned = !m.metaEdit.Pinned
+			return m, nil
+		case "ctrl+s":
+			return m.submitMeta()
+		case "enter":
+			if m.metaField == metaTags {
+				return m.submitMeta()
+			}
+		}
+	}
+	var cmd tea.Cmd
+	if m.metaField == metaNotes {
+		m.taskInput, cmd = m.taskInput.Update(msg)
+	} else {
+		m.nameInput, cmd = m.nameInput.Update(msg)
+	}
+	return m, cmd
+}
+
+func (m Model) submitMeta() (tea.Model, tea.Cmd) {
+	meta := m.metaEdit
+	meta.Tags = parseTags(m.nameInput.Value())
+	meta.Notes = strings.TrimSpace(m.taskInput.Value())
+	m.saveMeta(m.metaPath, meta)
+	m.state = stateNormal
+	m.nameInput.Blur()
+	m.taskInput.Blur()
+	return m, nil
+}
+
+func (m Model) renderMetaOver(base string) string {
+	var b strings.Builder
+	b.WriteString(detailHeadStyle.Render("EDIT SESSION") + "  " + dimStyle.Render(filepath.Base(m.metaPath)) + "\n\n")
+	b.WriteString(labelStyle.Render("TAGS") + "\n")
+	b.WriteString(m.nameInput.View() + "\n\n")
+	b.WriteString(labelStyle.Render("PRIORITY ") + priorityLabel(m.metaEdit.Priority) + "\n")
+	pinned := dimStyle.Render("no")
+	if m.metaEdit.Pinned {
+		pinned = okStyle.Render("★ yes")
+	}
+	b.WriteString(labelStyle.Render("PINNED   ") + pinned + "\n\n")
+	b.WriteString(labelStyle.Render("NOTES") + "\n")
+	b.WriteString(m.taskInput.View() + "\n")
+	b.WriteString("\n" + dimStyle.Render("ctrl+p priority · ctrl+o pin · kept while the worktree exists"))
+
+	modal := modalStyle.Render(b.String())
+	return lipgloss.Place(m.width, m.height, lipgloss.Center, lipgloss.Center, modal,
+		lipgloss.WithWhitespaceBackground(lipgloss.Color("0")),
+	)
+}
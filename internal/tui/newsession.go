@@ -0,0 +1,290 @@
+package tui
+
+import (
+	"sort"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+
+	"deckard/internal/git"
+	"deckard/internal/model"
+	"deckard/internal/prompt"
+	"deckard/internal/tmux"
+)
+
+// newField is the focused input of the new-branch form.
+type newField int
+
+const (
+	fieldName newField = iota
+	fieldBase
+	fieldTask
+)
+
+// launch describes how the agent of a session being created should start.
+type launch struct {
+	prompt string
+	attach bool     // false leaves the agent running in the background
+	args   []string // extra agent arguments, e.g. --resume <id>
+}
+
+type sessionStartedMsg struct {
+	slug string
+	err  error
+}
+
+func startDetachedCmd(s model.Session, opts tmux.Options) tea.Cmd {
+	return func() tea.Msg {
+		err := tmux.EnsureSession(s.Slug, s.Path, opts)
+		return sessionStartedMsg{slug: s.Slug, err: err}
+	}
+}
+
+// startAgent starts the agent for a newly created worktree according to the
+// pending launch, then clears it.
+func (m Model) startAgent(s model.Session) (Model, tea.Cmd) {
+	l := m.pendingLaunch
+	m.pendingLaunch = launch{attach: true}
+	m.state = stateNormal
+	opts := m.launchOptions(s.Path, l.prompt)
+	opts.Command = append(opts.Command[:len(opts.Command):len(opts.Command)], l.args...)
+	if l.attach {
+		return m, ensureAndAttachCmd(s, opts)
+	}
+	return m, startDetachedCmd(s, opts)
+}
+
+// templateNames returns the configured prompt templates in stable order.
+func (m Model) templateNames() []string {
+	names := make([]string, 0, len(m.cfg.Prompts))
+	for name := range m.cfg.Prompts {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+func (m *Model) focusNewField(f newField) tea.Cmd {
+	m.newField = f
+	m.nameInput.Blur()
+	m.baseInput.Blur()
+	m.taskInput.Blur()
+	switch f {
+	case fieldBase:
+		return m.baseInput.Focus()
+	case fieldTask:
+		return m.taskInput.Focus()
+	default:
+		return m.nameInput.Focus()
+	}
+}
+
+func (m Model) updateNewSession(msg tea.Msg) (tea.Model, tea.Cmd) {
+	switch msg := msg.(type) {
+	case tea.KeyMsg:
+		switch msg.String() {
+		case "esc":
+			m.state = stateNormal
+			m.inputErr = ""
+			m.focusNewField(fieldName)
+			m.nameInput.Blur()
+			return m, nil
+		case "tab", "shift+tab":
+			if msg.String() == "tab" {
+				m.newMode = (m.newMode + 1) % 3
+			} else {
+				m.newMode = (m.newMode + 2) % 3
+			}
+			return m.enterNewMode()
+		case "up", "down":
+			if m.newMode != newModeBranch {
+				if msg.String() == "up" {
+					m.picker.move(-1)
+				} else {
+					m.picker.move(1)
+				}
+				return m, nil
+			}
+			// In new-branch mode the arrows move between fields, except
+			// inside the task where they move the cursor until its edge.
+			if m.newField == fieldTask {
+				info := m.taskInput.LineInfo()
+				if msg.String() == "down" || m.taskInput.Line() > 0 || info.RowOffset > 0 {
+					break
+				}
+				return m, m.focusNewField(fieldBase)
+			}
+			if msg.String() == "down" {
+				return m, m.focusNewField(m.newField + 1)
+			}
+			if m.newField > fieldName {
+				return m, m.focusNewField(m.newField - 1)
+			}
+			return m, nil
+		case "ctrl+b":
+			// Stack the new branch on the selected session's branch.
+			if s := m.selectedSession(); m.newMode == newModeBranch && s != nil && s.Branch != "detached" {
+				m.baseInput.SetValue(s.Branch)
+			}
+			return m, nil
+		case "ctrl+t":
+			// Cycle through the prompt templates into the task field.
+			names := m.templateNames()
+			if m.newMode != newModeBranch || len(names) == 0 {
+				return m, nil
+			}
+			m.templateIdx = (m.templateIdx + 1) % (len(names) + 1)
+			if m.templateIdx == len(names) {
+				m.taskInput.Reset()
+			} else {
+				m.taskInput.SetValue(m.cfg.Prompts[names[m.templateIdx]])
+			}
+			return m, nil
+		case "ctrl+s":
+			return m.submitNewSession(true)
+		case "ctrl+r":
+			return m.submitNewSession(false)
+		case "enter":
+			if m.newMode == newModeBranch && m.newField == fieldTask {
+				break
+			}
+			return m.submitNewSession(true)
+		}
+	}
+	var cmd tea.Cmd
+	if m.newMode == newModeBranch {
+		switch m.newField {
+		case fieldBase:
+			m.baseInput, cmd = m.baseInput.Update(msg)
+			return m, cmd
+		case fieldTask:
+			m.taskInput, cmd = m.taskInput.Update(msg)
+			return m, cmd
+		}
+	}
+	m.nameInput, cmd = m.nameInput.Update(msg)
+	if m.newMode != newModeBranch {
+		m.picker.filter(m.nameInput.Value())
+	}
+	return m, cmd
+}
+
+// submitNewSession creates the worktree for the current mode; once it is set
+// up, the agent starts with the task as its first prompt.
+func (m Model) submitNewSession(attach bool) (tea.Model, tea.Cmd) {
+	if m.repoRoot == "" {
+		m.inputErr = "could not determine git repo root"
+		return m, nil
+	}
+	if m.newMode != newModeBranch {
+		it := m.picker.selected()
+		if it == nil {
+			m.inputErr = "nothing selected"
+			return m, nil
+		}
+		m.inputErr = ""
+		m.pendingLaunch = launch{attach: attach}
+		return m, checkoutWorktreeCmd(m.repoRoot, *it)
+	}
+
+	branch := strings.TrimSpace(m.nameInput.Value())
+	if branch == "" {
+		m.inputErr = "branch name cannot be empty"
+		return m, nil
+	}
+	base := strings.TrimSpace(m.baseInput.Value())
+	task := strings.TrimSpace(m.taskInput.Value())
+	if !attach && task == "" {
+		m.inputErr = "a task is needed to start in the background"
+		return m, nil
+	}
+	m.inputErr = ""
+	m.pendingLaunch = launch{
+		prompt: prompt.Expand(task, prompt.Vars(branch, git.ResolveBase(m.repoRoot, base))),
+		attach: attach,
+	}
+	return m, createWorktreeCmd(m.repoRoot, branch, base)
+}
+
+// enterNewMode resets the modal input for the current mode and, for the
+// picker modes, starts loading candidates.
+func (m Model) enterNewMode() (tea.Model, tea.Cmd) {
+	m.inputErr = ""
+	m.nameInput.Reset()
+	m.focusNewField(fieldName)
+	m.picker = picker{loading: true}
+	switch m.newMode {
+	case newModeExisting:
+		m.nameInput.Placeholder = "filter remote branches"
+		return m, loadRemoteBranchesCmd(m.repoRoot)
+	case newModeMR:
+		m.nameInput.Placeholder = "filter open MRs"
+		return m, loadOpenMRsCmd()
+	default:
+		m.nameInput.Placeholder = "e.g. phase-2-gitlab-mr-linking"
+		m.picker.loading = false
+		return m, nil
+	}
+}
+
+// resetNewSession prepares an empty new-session modal.
+func (m Model) resetNewSession() (tea.Model, tea.Cmd) {
+	m.state = stateNewSession
+	m.newMode = newModeBranch
+	m.baseInput.Reset()
+	m.taskInput.Reset()
+	m.templateIdx = -1
+	m.inputErr = ""
+	m.nameInput.Placeholder = "e.g. phase-2-gitlab-mr-linking"
+	m.nameInput.Reset()
+	return m, m.focusNewField(fieldName)
+}
+
+func (m Model) renderModalOver(base string) string {
+	var b strings.Builder
+	b.WriteString(detailHeadStyle.Render("NEW SESSION") + "\n\n")
+	var tabs []string
+	for _, mode := range []newMode{newModeBranch, newModeExisting, newModeMR} {
+		if mode == m.newMode {
+			tabs = append(tabs, okStyle.Render(mode.label()))
+		} else {
+			tabs = append(tabs, dimStyle.Render(mode.label()))
+		}
+	}
+	b.WriteString(strings.Join(tabs, dimStyle.Render(" · ")) + "\n\n")
+
+	if m.newMode == newModeBranch {
+		b.WriteString(labelStyle.Render("BRANCH NAME") + "\n")
+		b.WriteString(m.nameInput.View() + "\n\n")
+		b.WriteString(labelStyle.Render("BASE") + "\n")
+		b.WriteString(m.baseInput.View() + "\n")
+		if s := m.selectedSession(); s != nil && s.Branch != "detached" {
+			b.WriteString(dimStyle.Render("ctrl+b stack on "+s.Branch) + "\n")
+		}
+		b.WriteString("\n" + labelStyle.Render("TASK"))
+		if names := m.templateNames(); m.templateIdx >= 0 && m.templateIdx < len(names) {
+			b.WriteString(dimStyle.Render("  template: " + names[m.templateIdx]))
+		}
+		b.WriteString("\n" + m.taskInput.View() + "\n")
+		if len(m.cfg.Prompts) > 0 {
+			b.WriteString(dimStyle.Render("ctrl+t template · {{branch}} {{ticket}} {{base}}") + "\n")
+		}
+	} else {
+		b.WriteString(m.nameInput.View() + "\n\n")
+		b.WriteString(m.picker.view(50))
+	}
+	if m.inputErr != "" {
+		b.WriteString("\n" + errStyle.Render(m.inputErr) + "\n")
+	}
+	if m.newMode == newModeBranch {
+		b.WriteString("\n" + dimStyle.Render("creates .claude/worktrees/<slug> · opens claude"))
+	} else {
+		b.WriteString("\n" + dimStyle.Render("fetches the branch · reuses a local branch if present"))
+	}
+
+	modal := modalStyle.Render(b.String())
+	return lipgloss.Place(m.width, m.height, lipgloss.Center, lipgloss.Center, modal,
+		lipgloss.WithWhitespaceBackground(lipgloss.Color("0")),
+	)
+}
@@ -0,0 +1,196 @@
+package tui
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	"github.com/charmbracelet/bubbles/textinput"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/sahilm/fuzzy"
+
+	"deckard/internal/tmux"
+)
+
+// Pick is the compact session switcher `deckard pick` runs in a tmux popup.
+// Choosing a session switches the tmux client to it.
+type Pick struct {
+	client   string // tmux client to switch
+	current  string // session the client is on
+	sessions []tmux.Session
+	waiting  []bool // nil until checked
+	shown    []int  // indexes into sessions, best match first
+	cursor   int
+	input    textinput.Model
+	err      string
+	height   int
+}
+
+type pickSessionsMsg struct {
+	sessions []tmux.Session
+	err      error
+}
+
+type pickWaitingMsg struct {
+	waiting []bool
+}
+
+type pickSwitchedMsg struct {
+	err error
+}
+
+// NewPick returns the switcher for client, which is on session current.
+func NewPick(client, current string) Pick {
+	ti := textinput.New()
+	ti.Prompt = "› "
+	ti.Placeholder = "session"
+	ti.Focus()
+	return Pick{client: client, current: current, input: ti, height: 20}
+}
+
+func (p Pick) Init() tea.Cmd {
+	return tea.Batch(textinput.Blink, func() tea.Msg {
+		sessions, err := tmux.ListSessions()
+		return pickSessionsMsg{sessions: sessions, err: err}
+	})
+}
+
+func pickWaitingCmd(sessions []tmux.Session) tea.Cmd {
+	return func() tea.Msg {
+		names := make([]string, len(sessions))
+		for i, s := range sessions {
+			names[i] = s.Name
+		}
+		return pickWaitingMsg{waiting: tmux.Waiting(names)}
+	}
+}
+
+// filter works out which sessions match the query. With no query, sessions
+// waiting for input come first.
+func (p *Pick) filter() {
+	p.shown = p.shown[:0]
+	q := strings.TrimSpace(p.input.Value())
+	if q == "" {
+		for pass := 0; pass < 2; pass++ {
+			for i := range p.sessions {
+				if p.isWaiting(i) == (pass == 0) {
+					p.shown = append(p.shown, i)
+				}
+			}
+		}
+	} else {
+		names := make([]string, len(p.sessions))
+		for i, s := range p.sessions {
+			names[i] = s.Name
+		}
+		for _, match := range fuzzy.Find(q, names) {
+			p.shown = append(p.shown, match.Index)
+		}
+	}
+	p.cursor = max(0, min(p.cursor, len(p.shown)-1))
+}
+
+func (p Pick) isWaiting(i int) bool {
+	return p.waiting != nil && p.waiting[i]
+}
+
+func (p Pick) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	switch msg := msg.(type) {
+	case tea.WindowSizeMsg:
+		p.height = msg.Height
+		return p, nil
+
+	case pickSessionsMsg:
+		if msg.err != nil {
+			p.err = msg.err.Error()
+			return p, nil
+		}
+		p.sessions = msg.sessions
+		p.filter()
+		return p, pickWaitingCmd(p.sessions)
+
+	case pickWaitingMsg:
+		if len(msg.waiting) == len(p.sessions) {
+			p.waiting = msg.waiting
+			p.filter()
+		}
+		return p, nil
+
+	case pickSwitchedMsg:
+		if msg.err != nil {
+			p.err = msg.err.Error()
+			return p, nil
+		}
+		return p, tea.Quit
+
+	case tea.KeyMsg:
+		switch msg.String() {
+		case "esc", "ctrl+c":
+			return p, tea.Quit
+		case "up", "ctrl+p":
+			if p.cursor > 0 {
+				p.cursor--
+			}
+			return p, nil
+		case "down", "ctrl+n":
+			if p.cursor < len(p.shown)-1 {
+				p.cursor++
+			}
+			return p, nil
+		case "enter":
+			if len(p.shown) == 0 {
+				return p, nil
+			}
+			name := p.sessions[p.shown[p.cursor]].Name
+			if name == p.current {
+				return p, tea.Quit
+			}
+			client := p.client
+			return p, func() tea.Msg {
+				return pickSwitchedMsg{err: tmux.SwitchClient(client, name)}
+			}
+		}
+	}
+	var cmd tea.Cmd
+	p.input, cmd = p.input.Update(msg)
+	p.filter()
+	return p, cmd
+}
+
+func (p Pick) View() string {
+	var b strings.Builder
+	b.WriteString(p.input.View() + "\n\n")
+	if p.err != "" {
+		b.WriteString(errStyle.Render(p.err) + "\n")
+	}
+	if len(p.sessions) == 0 && p.err == "" {
+		b.WriteString(dimStyle.Render("no sessions") + "\n")
+	}
+
+	// Keep the cursor in view: two lines for the input, one for the footer.
+	rows := max(1, p.height-4)
+	start := max(0, p.cursor-rows+1)
+	for k := start; k < len(p.shown) && k < start+rows; k++ {
+		i := p.shown[k]
+		s := p.sessions[i]
+		mark := dimStyle.Render("·")
+		switch {
+		case p.waiting == nil:
+			mark = dimStyle.Render(" ")
+		case p.waiting[i]:
+			mark = warnStyle.Render("▲")
+		}
+		name := s.Name
+		if s.Name == p.current {
+			name += dimStyle.Render(" (here)")
+		}
+		line := fmt.Sprintf("%s %s  %s", mark, name, dimStyle.Render(filepath.Base(s.Path)))
+		if k == p.cursor {
+			b.WriteString(labelStyle.Render("▌") + boldStyle.Render(line) + "\n")
+		} else {
+			b.WriteString(" " + line + "\n")
+		}
+	}
+	b.WriteString("\n" + dimStyle.Render("▲ needs input · Enter switch · Esc close"))
+	return b.String()
+}
@@ -0,0 +1,168 @@
+package tui
+
+import (
+	"fmt"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/sahilm/fuzzy"
+
+	"deckard/internal/git"
+	"deckard/internal/gitlab"
+)
+
+// newMode selects what the new-session modal creates a worktree from.
+type newMode int
+
+const (
+	newModeBranch   newMode = iota // a new branch
+	newModeExisting                // an existing remote branch
+	newModeMR                      // the source branch of an open MR
+)
+
+func (n newMode) label() string {
+	switch n {
+	case newModeExisting:
+		return "EXISTING BRANCH"
+	case newModeMR:
+		return "FROM MR"
+	default:
+		return "NEW BRANCH"
+	}
+}
+
+// pickItem is a candidate in the fuzzy picker.
+type pickItem struct {
+	label  string // matched against the query
+	detail string // shown dimmed after the label
+	branch string
+	mrIID  int
+}
+
+// picker is a fuzzy-filtered list of candidates driven by a text input.
+type picker struct {
+	items   []pickItem
+	matches []int // indexes into items, best match first
+	cursor  int
+	loading bool
+	err     string
+}
+
+const pickerRows = 8
+
+type pickItemsLoadedMsg struct {
+	mode  newMode
+	items []pickItem
+	err   error
+}
+
+func loadRemoteBranchesCmd(repoRoot string) tea.Cmd {
+	return func() tea.Msg {
+		branches, err := git.RemoteBranches(repoRoot)
+		items := make([]pickItem, len(branches))
+		for i, b := range branches {
+			items[i] = pickItem{label: b, branch: b}
+		}
+		return pickItemsLoadedMsg{mode: newModeExisting, items: items, err: err}
+	}
+}
+
+func loadOpenMRsCmd() tea.Cmd {
+	return func() tea.Msg {
+		mrs, err := gitlab.ListOpenMRs()
+		items := make([]pickItem, len(mrs))
+		for i, mr := range mrs {
+			items[i] = pickItem{
+				label:  fmt.Sprintf("!%d %s", mr.IID, mr.Title),
+				detail: mr.SourceBranch + " · @" + mr.Author,
+				branch: mr.SourceBranch,
+				mrIID:  mr.IID,
+			}
+		}
+		return pickItemsLoadedMsg{mode: newModeMR, items: items, err: err}
+	}
+}
+
+func (p *picker) setItems(items []pickItem, query string) {
+	p.items = items
+	p.loading = false
+	p.filter(query)
+}
+
+// filter re-ranks the items against query; an empty query keeps input order.
+func (p *picker) filter(query string) {
+	p.cursor = 0
+	p.matches = p.matches[:0]
+	if strings.TrimSpace(query) == "" {
+		for i := range p.items {
+			p.matches = append(p.matches, i)
+		}
+		return
+	}
+	labels := make([]string, len(p.items))
+	for i, it := range p.items {
+		labels[i] = it.label + " " + it.branch
+	}
+	for _, m := range fuzzy.Find(query, labels) {
+		p.matches = append(p.matches, m.Index)
+	}
+}
+
+func (p *picker) move(delta int) {
+	p.cursor += delta
+	if p.cursor < 0 {
+		p.cursor = 0
+	}
+	if p.cursor >= len(p.matches) {
+		p.cursor = len(p.matches) - 1
+	}
+}
+
+func (p picker) selected() *pickItem {
+	if p.cursor < 0 || p.cursor >= len(p.matches) {
+		return nil
+	}
+	return &p.items[p.matches[p.cursor]]
+}
+
+func (p picker) view(width int) string {
+	switch {
+	case p.loading:
+		return dimStyle.Render("loading…") + "\n"
+	case p.err != "":
+		return errStyle.Render(p.err) + "\n"
+	case len(p.matches) == 0:
+		return dimStyle.Render("no matches") + "\n"
+	}
+
+	// Keep the cursor inside a window of pickerRows.
+	start := 0
+	if p.cursor >= pickerRows {
+		start = p.cursor - pickerRows + 1
+	}
+	end := start + pickerRows
+	if end > len(p.matches) {
+		end = len(p.matches)
+	}
+
+	var b strings.Builder
+	for i := start; i < end; i++ {
+		it := p.items[p.matches[i]]
+		label := it.label
+		if limit := width - 2; limit > 0 && len([]rune(label)) > limit {
+			label = string([]rune(label)[:limit-1]) + "…"
+		}
+		if i == p.cursor {
+			b.WriteString(labelStyle.Render("▌") + boldStyle.Render(label) + "\n")
+		} else {
+			b.WriteString(" " + label + "\n")
+		}
+		if it.detail != "" {
+			b.WriteString(dimStyle.Render("  "+it.detail) + "\n")
+		}
+	}
+	if len(p.matches) > end {
+		b.WriteString(dimStyle.Render(fmt.Sprintf("  +%d more", len(p.matches)-end)) + "\n")
+	}
+	return b.String()
+}
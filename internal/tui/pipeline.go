@@ -0,0 +1,347 @@
+package tui
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/charmbracelet/bubbles/viewport"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+
+	"deckard/internal/gitlab"
+	"deckard/internal/model"
+)
+
+// — pipeline messages ———————————————————————————————————————————————————————
+
+type pipelineLoadedMsg struct {
+	pipeline *model.Pipeline
+	err      error
+}
+
+type jobLogLoadedMsg struct {
+	jobID    int
+	sections []model.LogSection
+	err      error
+}
+
+type pipelineActionMsg struct {
+	label string
+	err   error
+}
+
+// — pipeline commands ———————————————————————————————————————————————————————
+
+func fetchPipelineCmd(branch string, id int) tea.Cmd {
+	return func() tea.Msg {
+		p, err := gitlab.FetchPipeline(branch, id)
+		return pipelineLoadedMsg{pipeline: p, err: err}
+	}
+}
+
+func fetchJobLogCmd(job model.Job) tea.Cmd {
+	return func() tea.Msg {
+		raw, err := gitlab.FetchJobLog(job.ID)
+		if err != nil {
+			return jobLogLoadedMsg{jobID: job.ID, err: err}
+		}
+		return jobLogLoadedMsg{
+			jobID:    job.ID,
+			sections: gitlab.ParseJobLog(raw, job.Status == "failed"),
+		}
+	}
+}
+
+func pipelineActionCmd(label string, fn func() error) tea.Cmd {
+	return func() tea.Msg {
+		return pipelineActionMsg{label: label, err: fn()}
+	}
+}
+
+// — pipeline state ——————————————————————————————————————————————————————————
+
+// openPipeline switches to the pipeline view for s and starts loading it.
+func (m Model) openPipeline(s *model.Session) (Model, tea.Cmd) {
+	id := 0
+	if s.MR != nil {
+		id = s.MR.PipelineID
+	}
+	m.state = statePipeline
+	m.pipeline = nil
+	m.pipelineErr = ""
+	m.pipelineNote = ""
+	m.pipelineCursor = 0
+	m.pipelineLogJob = 0
+	m.pipelineLoading = true
+	m.pipelineLog = viewport.New(0, 0)
+	m.sizePipelineLog()
+	return m, fetchPipelineCmd(s.Branch, id)
+}
+
+func (m *Model) sizePipelineLog() {
+	jw := m.width / 3
+	m.pipelineLog.Width = m.width - jw - 4
+	m.pipelineLog.Height = m.height - 6
+}
+
+func (m Model) selectedJob() *model.Job {
+	if m.pipeline == nil || m.pipelineCursor < 0 || m.pipelineCursor >= len(m.pipeline.Jobs) {
+		return nil
+	}
+	return &m.pipeline.Jobs[m.pipelineCursor]
+}
+
+func (m Model) handlePipelineMsg(msg tea.Msg) (Model, tea.Cmd, bool) {
+	switch msg := msg.(type) {
+	case pipelineLoadedMsg:
+		m.pipelineLoading = false
+		if msg.err != nil {
+			m.pipelineErr = msg.err.Error()
+			return m, nil, true
+		}
+		m.pipelineErr = ""
+		m.pipeline = msg.pipeline
+		if m.pipelineCursor >= len(m.pipeline.Jobs) {
+			m.pipelineCursor = 0
+		}
+		// Jump straight to the first failing job and tail its log.
+		if m.pipelineLogJob == 0 {
+			for i, j := range m.pipeline.Jobs {
+				if j.Status == "failed" && !j.AllowFailure {
+					m.pipelineCursor = i
+					m.pipelineLogJob = j.ID
+					return m, fetchJobLogCmd(j), true
+				}
+			}
+		}
+		return m, nil, true
+
+	case jobLogLoadedMsg:
+		if msg.jobID != m.pipelineLogJob {
+			return m, nil, true
+		}
+		if msg.err != nil {
+			m.pipelineLog.SetContent(errStyle.Render(msg.err.Error()))
+			return m, nil, true
+		}
+		m.pipelineLog.SetContent(renderLogSections(msg.sections, m.pipelineLog.Width))
+		m.pipelineLog.GotoBottom()
+		return m, nil, true
+
+	case pipelineActionMsg:
+		if msg.err != nil {
+			m.pipelineErr = msg.err.Error()
+			return m, nil, true
+		}
+		m.pipelineErr = ""
+		m.pipelineNote = msg.label
+		if m.pipeline == nil {
+			return m, nil, true
+		}
+		return m, fetchPipelineCmd(m.pipeline.Ref, m.pipeline.ID), true
+	}
+	return m, nil, false
+}
+
+func (m Model) updatePipeline(msg tea.Msg) (tea.Model, tea.Cmd) {
+	switch msg := msg.(type) {
+	case tea.KeyMsg:
+		switch msg.String() {
+		case "esc", "q":
+			m.state = stateNormal
+			return m, nil
+		case "up", "k":
+			if m.pipelineCursor > 0 {
+				m.pipelineCursor--
+			}
+			return m, nil
+		case "down", "j":
+			if m.pipeline != nil && m.pipelineCursor < len(m.pipeline.Jobs)-1 {
+				m.pipelineCursor++
+			}
+			return m, nil
+		case "enter":
+			if j := m.selectedJob(); j != nil {
+				m.pipelineLogJob = j.ID
+				m.pipelineLog.SetContent(dimStyle.Render("LOADING LOG…"))
+				return m, fetchJobLogCmd(*j)
+			}
+			return m, nil
+		case "f":
+			if m.pipeline != nil {
+				m.pipelineNote = ""
+				return m, fetchPipelineCmd(m.pipeline.Ref, m.pipeline.ID)
+			}
+			return m, nil
+		case "r":
+			if j := m.selectedJob(); j != nil {
+				id := j.ID
+				return m, pipelineActionCmd("retried "+j.Name, func() error { return gitlab.RetryJob(id) })
+			}
+			return m, nil
+		case "x":
+			if j := m.selectedJob(); j != nil {
+				id := j.ID
+				return m, pipelineActionCmd("canceled "+j.Name, func() error { return gitlab.CancelJob(id) })
+			}
+			return m, nil
+		case "R":
+			if m.pipeline != nil {
+				id := m.pipeline.ID
+				return m, pipelineActionCmd("retried pipeline", func() error { return gitlab.RetryPipeline(id) })
+			}
+			return m, nil
+		case "X":
+			if m.pipeline != nil {
+				id := m.pipeline.ID
+				return m, pipelineActionCmd("canceled pipeline", func() error { return gitlab.CancelPipeline(id) })
+			}
+			return m, nil
+		case "o":
+			if j := m.selectedJob(); j != nil && j.WebURL != "" {
+				return m, openURLCmd(j.WebURL)
+			}
+			if m.pipeline != nil && m.pipeline.WebURL != "" {
+				return m, openURLCmd(m.pipeline.WebURL)
+			}
+			return m, nil
+		}
+	}
+	// Remaining keys (pgup/pgdn, mouse) scroll the log.
+	var cmd tea.Cmd
+	m.pipelineLog, cmd = m.pipelineLog.Update(msg)
+	return m, cmd
+}
+
+// — pipeline rendering ——————————————————————————————————————————————————————
+
+func (m Model) renderPipeline() string {
+	s := m.selectedSession()
+	var head strings.Builder
+	head.WriteString(detailHeadStyle.Render("PIPELINE"))
+	if s != nil {
+		head.WriteString("  " + dimStyle.Render(strings.ToUpper(s.Slug)))
+	}
+	if m.pipeline != nil {
+		head.WriteString(fmt.Sprintf("  #%d  %s", m.pipeline.ID, pipelineLabel(m.pipeline.Status)))
+	}
+	if m.pipelineNote != "" {
+		head.WriteString("  " + okStyle.Render(m.pipelineNote))
+	}
+	if m.pipelineErr != "" {
+		head.WriteString("  " + errStyle.Render(m.pipelineErr))
+	}
+
+	jw := m.width / 3
+	jobs := lipgloss.NewStyle().
+		Width(jw).
+		Height(m.height - 5).
+		PaddingLeft(2).
+		Render(m.renderJobs(jw - 2))
+
+	logPane := lipgloss.NewStyle().
+		Border(lipgloss.NormalBorder(), false, false, false, true).
+		BorderForeground(lipgloss.Color("86")).
+		PaddingLeft(2).
+		Height(m.height - 5).
+		Render(m.pipelineLog.View())
+
+	body := lipgloss.JoinHorizontal(lipgloss.Top, jobs, logPane)
+	return lipgloss.JoinVertical(lipgloss.Left,
+		lipgloss.NewStyle().Padding(1, 2, 0, 2).Render(head.String()),
+		"",
+		body,
+		m.renderHelp(),
+	)
+}
+
+func (m Model) renderJobs(width int) string {
+	if m.pipelineLoading {
+		return dimStyle.Render("LOADING PIPELINE…")
+	}
+	if m.pipeline == nil {
+		return dimStyle.Render("NO PIPELINE")
+	}
+
+	var b strings.Builder
+	stage := ""
+	for i, j := range m.pipeline.Jobs {
+		if j.Stage != stage {
+			stage = j.Stage
+			if i > 0 {
+				b.WriteString("\n")
+			}
+			b.WriteString(sectionSep(strings.ToUpper(stage), width) + "\n")
+		}
+		name := j.Name
+		if j.AllowFailure {
+			name += dimStyle.Render(" (allowed)")
+		}
+		line := fmt.Sprintf("%s %s %s", jobIcon(j.Status), name, dimStyle.Render(formatDuration(j.Duration)))
+		if i == m.pipelineCursor {
+			line = labelStyle.Render("▌") + boldStyle.Render(line)
+		} else {
+			line = " " + line
+		}
+		b.WriteString(line + "\n")
+	}
+	return b.String()
+}
+
+func jobIcon(status string) string {
+	switch status {
+	case "success":
+		return okStyle.Render("◆")
+	case "failed":
+		return errStyle.Render("✕")
+	case "running":
+		return warnStyle.Render("~")
+	case "pending", "created", "waiting_for_resource", "preparing", "scheduled":
+		return warnStyle.Render("◇")
+	case "manual":
+		return dimStyle.Render("▷")
+	default:
+		return dimStyle.Render("·")
+	}
+}
+
+func formatDuration(d time.Duration) string {
+	if d <= 0 {
+		return ""
+	}
+	d = d.Round(time.Second)
+	if d < time.Minute {
+		return fmt.Sprintf("%ds", int(d.Seconds()))
+	}
+	return fmt.Sprintf("%dm%02ds", int(d.Minutes()), int(d.Seconds())%60)
+}
+
+// renderLogSections collapses every section except the failing one into a
+// single header line, so the relevant output is what the user sees first.
+func renderLogSections(sections []model.LogSection, width int) string {
+	failingStyle := lipgloss.NewStyle().Bold(true).Foreground(lipgloss.Color("196"))
+
+	var b strings.Builder
+	for _, sec := range sections {
+		if sec.Name == "" {
+			for _, l := range sec.Lines {
+				b.WriteString(l + "\n")
+			}
+			continue
+		}
+		header := sec.Header
+		if header == "" {
+			header = sec.Name
+		}
+		if !sec.Failing {
+			b.WriteString(dimStyle.Render(fmt.Sprintf("▸ %s  (%d lines)", header, len(sec.Lines))) + "\n")
+			continue
+		}
+		b.WriteString(failingStyle.Render("▾ "+header) + "\n")
+		for _, l := range sec.Lines {
+			b.WriteString(errStyle.Render("│ ") + l + "\n")
+		}
+	}
+	return lipgloss.NewStyle().Width(width).Render(b.String())
+}
@@ -0,0 +1,117 @@
+package tui
+
+import (
+	"fmt"
+	"net"
+	"strings"
+	"time"
+
+	"deckard/internal/config"
+	"deckard/internal/model"
+)
+
+// applyPorts gives every worktree its reserved port block, reserving one for
+// worktrees that have none, keeping the listening state fetchSessions probed.
+// Blocks of worktrees that no longer exist are released.
+func (m *Model) applyPorts() {
+	if m.store == nil {
+		return
+	}
+	live := map[string]bool{}
+	changed := false
+	for i := range m.sessions {
+		s := &m.sessions[i]
+		live[s.Path] = true
+		block, ok := m.store.Ports[s.Path]
+		start := block.Start
+		if !ok {
+			var err error
+			if start, err = m.store.AllocPorts(s.Path, m.cfg.Ports.From, m.cfg.Ports.To, m.cfg.Ports.PerWorktree); err != nil {
+				m.setNotice(err.Error(), true)
+				continue
+			}
+			changed = true
+		}
+		// A block reserved since the fetch hasn't been probed yet.
+		if len(s.Ports) == 0 || s.Ports[0].Number != start {
+			s.Ports = namedPorts(m.cfg.Ports, start, false)
+		}
+	}
+	for path := range m.store.Ports {
+		if !live[path] && worktreeGone(path) {
+			delete(m.store.Ports, path)
+			changed = true
+		}
+	}
+	if changed {
+		if err := m.store.Save(); err != nil {
+			m.setNotice(err.Error(), true)
+		}
+	}
+}
+
+// reservePorts reserves a port block for a new worktree at path.
+func (m *Model) reservePorts(path string) {
+	if m.store == nil {
+		return
+	}
+	if _, ok := m.store.Ports[path]; ok {
+		return
+	}
+	if _, err := m.store.AllocPorts(path, m.cfg.Ports.From, m.cfg.Ports.To, m.cfg.Ports.PerWorktree); err != nil {
+		m.setNotice(err.Error(), true)
+		return
+	}
+	if err := m.store.Save(); err != nil {
+		m.setNotice(err.Error(), true)
+	}
+}
+
+// portEnv returns the port variables of the worktree at path; nil if it has
+// no block.
+func (m Model) portEnv(path string) []string {
+	if m.store == nil {
+		return nil
+	}
+	block, ok := m.store.Ports[path]
+	if !ok {
+		return nil
+	}
+	return m.cfg.Ports.Environ(block.Start)
+}
+
+// namedPorts returns the named ports of the block at start, checking which
+// are listening if probe is set.
+func namedPorts(c config.Ports, start int, probe bool) []model.Port {
+	var ports []model.Port
+	for k, name := range c.Env {
+		if k >= c.PerWorktree {
+			break
+		}
+		ports = append(ports, model.Port{Name: name, Number: start + k, Listening: probe && listening(start+k)})
+	}
+	return ports
+}
+
+// listening reports whether something accepts connections on the local port.
+func listening(port int) bool {
+	conn, err := net.DialTimeout("tcp", fmt.Sprintf("localhost:%d", port), 50*time.Millisecond)
+	if err != nil {
+		return false
+	}
+	conn.Close()
+	return true
+}
+
+// renderPorts renders a session's named ports as localhost URLs.
+func renderPorts(ports []model.Port) string {
+	var b strings.Builder
+	for _, p := range ports {
+		state := dimStyle.Render("· closed")
+		if p.Listening {
+			state = okStyle.Render("◆ listening")
+		}
+		b.WriteString(labelStyle.Render(fmt.Sprintf("%-9s", p.Name)) + fmt.Sprintf("http://localhost:%d  ", p.Number) + state + "\n")
+	}
+	return b.String()
+}
@@ -0,0 +1,162 @@
+package tui
+
+import (
+	"fmt"
+
+	tea "github.com/charmbracelet/bubbletea"
+
+	"deckard/internal/model"
+	"deckard/internal/tmux"
+)
+
+type fleetRestoredMsg struct {
+	restored int
+	errs     []error
+}
+
+// recordLive remembers which worktrees have a running agent, with its profile
+// and the latest conversation fetchSessions found. An agent that exits is forgotten; one whose
+// session merely vanished, as when the tmux server goes away, is kept so it
+// can be restored.
+func (m *Model) recordLive() {
+	if m.store == nil {
+		return
+	}
+	live := map[string]bool{}
+	changed := false
+	for _, s := range m.sessions {
+		live[s.Path] = true
+		old, known := m.store.Live[s.Path]
+		switch {
+		case s.TmuxRunning && !s.AgentExited:
+			entry := old
+			entry.Slug, entry.Agent = s.Slug, s.Meta.Agent
+			if s.Conversation != "" {
+				entry.Conversation = s.Conversation
+			}
+			if !known || entry != old {
+				m.store.Live[s.Path] = entry
+				changed = true
+			}
+		case s.AgentExited && known:
+			delete(m.store.Live, s.Path)
+			changed = true
+		}
+	}
+	for path := range m.store.Live {
+		if !live[path] {
+			delete(m.store.Live, path)
+			changed = true
+		}
+	}
+	if changed {
+		if err := m.store.Save(); err != nil {
+			m.setNotice(err.Error(), true)
+		}
+	}
+}
+
+// forgetLive drops the record of s's running agent, e.g. when it is killed
+// on purpose.
+func (m *Model) forgetLive(slug string) {
+	if m.store == nil {
+		return
+	}
+	for path, l := range m.store.Live {
+		if l.Slug == slug {
+			delete(m.store.Live, path)
+		}
+	}
+	if err := m.store.Save(); err != nil {
+		m.setNotice(err.Error(), true)
+	}
+}
+
+// restorable returns the sessions that were running and no longer are.
+func (m Model) restorable() []model.Session {
+	if m.store == nil {
+		return nil
+	}
+	var out []model.Session
+	for _, s := range m.sessions {
+		if _, ok := m.store.Live[s.Path]; ok && !s.TmuxRunning && !s.AgentExited {
+			out = append(out, s)
+		}
+	}
+	return out
+}
+
+// restoreFleet starts every restorable session in the background, each with
+// its own agent profile resuming its last conversation.
+func (m Model) restoreFleet() (tea.Model, tea.Cmd) {
+	todo := m.restorable()
+	if len(todo) == 0 {
+		m.setNotice("nothing to restore", false)
+		return m, nil
+	}
+	opts := make([]tmux.Options, len(todo))
+	for i, s := range todo {
+		opts[i] = m.agentOptions(s)
+		var args []string
+		if id := m.store.Live[s.Path].Conversation; id != "" {
+			args = []string{"--resume", id}
+		} else {
+			args = resumeArgs(s.Path)
+		}
+		opts[i].Command = append(opts[i].Command[:len(opts[i].Command):len(opts[i].Command)], args...)
+	}
+	m.setNotice(fmt.Sprintf("restoring %d session(s)…", len(todo)), false)
+	return m, restoreFleetCmd(todo, opts)
+}
+
+// restoreFleetCmd starts the sessions one after another, so the first one
+// starts the tmux server alone.
+func restoreFleetCmd(sessions []model.Session, opts []tmux.Options) tea.Cmd {
+	return func() tea.Msg {
+		var msg fleetRestoredMsg
+		for i, s := range sessions {
+			if err := tmux.EnsureSession(s.Slug, s.Path, opts[i]); err != nil {
+				msg.errs = append(msg.errs, fmt.Errorf("%s: %w", s.Slug, err))
+				continue
+			}
+			msg.restored++
+		}
+		return msg
+	}
+}
+
+// offerRestore runs once, after the first refresh: it restores the fleet if
+// restore.auto is set, and otherwise points out that it can be.
+func (m *Model) offerRestore() tea.Cmd {
+	if m.restoreOffered {
+		return nil
+	}
+	m.restoreOffered = true
+	n := len(m.restorable())
+	if n == 0 {
+		return nil
+	}
+	if m.cfg.Restore.Auto {
+		rm, cmd := m.restoreFleet()
+		*m = rm.(Model)
+		return cmd
+	}
+	if m.notice == "" {
+		m.setNotice(fmt.Sprintf("%d session(s) were running before tmux went away — U restores them", n), false)
+	}
+	return nil
+}
+
+func (m Model) handleRestoreMsg(msg tea.Msg) (Model, tea.Cmd, bool) {
+	rm, ok := msg.(fleetRestoredMsg)
+	if !ok {
+		return m, nil, false
+	}
+	if len(rm.errs) > 0 {
+		m.setNotice(fmt.Sprintf("restored %d, failed: %v", rm.restored, rm.errs[0]), true)
+	} else {
+		m.setNotice(fmt.Sprintf("restored %d session(s)", rm.restored), false)
+	}
+	m.loading = true
+	return m, m.fetchSessions(), true
+}
@@ -0,0 +1,171 @@
+package tui
+
+import (
+	"fmt"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+
+	"deckard/internal/claude"
+	"deckard/internal/model"
+)
+
+// Choices of the resume modal before the conversations themselves.
+const (
+	resumeFresh    = iota // start a new conversation
+	resumeContinue        // claude --continue: the most recent conversation
+	resumeFixed           // number of fixed choices
+)
+
+type conversationsLoadedMsg struct {
+	path  string
+	convs []claude.Conversation
+	err   error
+}
+
+func loadConversationsCmd(path string) tea.Cmd {
+	return func() tea.Msg {
+		convs, err := claude.Conversations(path)
+		return conversationsLoadedMsg{path: path, convs: convs, err: err}
+	}
+}
+
+// startSession attaches to s, on window n (1-based; 0 for the agent's). If
+// the agent isn't running and the worktree has earlier conversations, it
+// first asks whether to resume one.
+func (m Model) startSession(s model.Session, n int) (tea.Model, tea.Cmd) {
+	if s.TmuxRunning && !s.AgentExited {
+		return m, m.attachCmd(s, n, nil)
+	}
+	m.resumeSession = s
+	m.resumeWindow = n
+	return m, loadConversationsCmd(s.Path)
+}
+
+// attachCmd starts s's agent if needed, with extra arguments, and attaches
+// to window n (0 for the agent's).
+func (m Model) attachCmd(s model.Session, n int, args []string) tea.Cmd {
+	opts := m.agentOptions(s)
+	opts.Command = append(opts.Command[:len(opts.Command):len(opts.Command)], args...)
+	if n > 0 {
+		return attachWindowCmd(s, opts, n)
+	}
+	return ensureAndAttachCmd(s, opts)
+}
+
+func (m Model) handleResumeMsg(msg tea.Msg) (Model, tea.Cmd, bool) {
+	cm, ok := msg.(conversationsLoadedMsg)
+	if !ok {
+		return m, nil, false
+	}
+	if cm.path != m.resumeSession.Path || m.state != stateNormal {
+		return m, nil, true
+	}
+	if cm.err != nil || len(cm.convs) == 0 {
+		return m, m.attachCmd(m.resumeSession, m.resumeWindow, nil), true
+	}
+	m.resumeConvs = cm.convs
+	m.resumeCursor = resumeFixed // the latest conversation
+	m.state = stateResume
+	return m, nil, true
+}
+
+func (m Model) updateResume(msg tea.Msg) (tea.Model, tea.Cmd) {
+	key, ok := msg.(tea.KeyMsg)
+	if !ok {
+		return m, nil
+	}
+	switch key.String() {
+	case "esc":
+		m.state = stateNormal
+	case "up", "k":
+		if m.resumeCursor > 0 {
+			m.resumeCursor--
+		}
+	case "down", "j":
+		if m.resumeCursor < resumeFixed+len(m.resumeConvs)-1 {
+			m.resumeCursor++
+		}
+	case "n":
+		m.resumeCursor = resumeFresh
+		return m.submitResume()
+	case "c":
+		m.resumeCursor = resumeContinue
+		return m.submitResume()
+	case "enter":
+		return m.submitResume()
+	}
+	return m, nil
+}
+
+func (m Model) submitResume() (tea.Model, tea.Cmd) {
+	var args []string
+	switch m.resumeCursor {
+	case resumeFresh:
+	case resumeContinue:
+		args = []string{"--continue"}
+	default:
+		args = []string{"--resume", m.resumeConvs[m.resumeCursor-resumeFixed].ID}
+	}
+	m.state = stateNormal
+	return m, m.attachCmd(m.resumeSession, m.resumeWindow, args)
+}
+
+// formatSize renders a byte count compactly, e.g. 1.2 MB.
+func formatSize(n int64) string {
+	switch {
+	case n >= 1<<20:
+		return fmt.Sprintf("%.1f MB", float64(n)/(1<<20))
+	case n >= 1<<10:
+		return fmt.Sprintf("%d KB", n>>10)
+	default:
+		return fmt.Sprintf("%d B", n)
+	}
+}
+
+// resumeVisible is how many conversations the modal lists at once.
+const resumeVisible = 8
+
+func (m Model) renderResumeOver(base string) string {
+	var b strings.Builder
+	b.WriteString(detailHeadStyle.Render("START AGENT") + "  " + dimStyle.Render(strings.ToUpper(m.resumeSession.Slug)) + "\n\n")
+
+	line := func(i int, text string) {
+		if i == m.resumeCursor {
+			b.WriteString(labelStyle.Render("▌") + boldStyle.Render(text) + "\n")
+		} else {
+			b.WriteString(" " + text + "\n")
+		}
+	}
+	line(resumeFresh, "new conversation")
+	line(resumeContinue, "continue the most recent")
+	b.WriteString("\n" + labelStyle.Render("RESUME") + "\n")
+
+	// Keep the cursor in view.
+	first := 0
+	if c := m.resumeCursor - resumeFixed; c >= resumeVisible {
+		first = c - resumeVisible + 1
+	}
+	width := 50
+	for k := first; k < len(m.resumeConvs) && k < first+resumeVisible; k++ {
+		c := m.resumeConvs[k]
+		prompt := strings.Join(strings.Fields(c.FirstPrompt), " ")
+		if prompt == "" {
+			prompt = "(no prompt)"
+		}
+		meta := c.Modified.Format("Jan 02 15:04") + "  " + fmt.Sprintf("%7s", formatSize(c.Size)) + "  "
+		if limit := width - len(meta); len([]rune(prompt)) > limit {
+			prompt = string([]rune(prompt)[:limit-1]) + "…"
+		}
+		line(resumeFixed+k, dimStyle.Render(meta)+prompt)
+	}
+	if more := len(m.resumeConvs) - first - resumeVisible; more > 0 {
+		b.WriteString(dimStyle.Render(fmt.Sprintf(" … %d more", more)) + "\n")
+	}
+
+	modal := modalStyle.Width(64).Render(b.String())
+	return lipgloss.Place(m.width, m.height, lipgloss.Center, lipgloss.Center, modal,
+		lipgloss.WithWhitespaceBackground(lipgloss.Color("0")),
+	)
+}
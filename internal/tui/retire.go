@@ -0,0 +1,202 @@
+package tui
+
+import (
+	"fmt"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+
+	"deckard/internal/git"
+	"deckard/internal/model"
+	"deckard/internal/tmux"
+)
+
+type retireSkip struct {
+	slug   string
+	reason string
+}
+
+type retireResultMsg struct {
+	removed []string
+	skipped []retireSkip
+	auto    bool
+}
+
+// summary is a one-line account of the result, for when the summary overlay
+// can't be shown.
+func (r retireResultMsg) summary() string {
+	var parts []string
+	if len(r.removed) > 0 {
+		parts = append(parts, "retired "+strings.Join(r.removed, ", "))
+	}
+	for _, sk := range r.skipped {
+		parts = append(parts, "skipped "+sk.slug+": "+sk.reason)
+	}
+	return strings.Join(parts, " · ")
+}
+
+// retireCandidates returns the sessions whose MR has merged, excluding the
+// main checkout.
+func (m Model) retireCandidates() []model.Session {
+	var out []model.Session
+	for _, s := range m.sessions {
+		if s.Path == m.repoRoot || s.MR == nil || s.MR.State != "merged" {
+			continue
+		}
+		out = append(out, s)
+	}
+	return out
+}
+
+// mergedHead returns the head commit s's merged MR covered, or "" if its MR
+// has not merged.
+func mergedHead(s model.Session) string {
+	if s.MR == nil || s.MR.State != "merged" {
+		return ""
+	}
+	return s.MR.SHA
+}
+
+// retireCmd removes each merged session that has no uncommitted or unpushed
+// work: it kills the tmux session, removes the worktree and deletes the branch.
+func retireCmd(repoRoot string, sessions []model.Session, auto bool) tea.Cmd {
+	return func() tea.Msg {
+		res := retireResultMsg{auto: auto}
+		for _, s := range sessions {
+			if reason := retireOne(repoRoot, s); reason != "" {
+				res.skipped = append(res.skipped, retireSkip{slug: s.Slug, reason: reason})
+				continue
+			}
+			res.removed = append(res.removed, s.Slug)
+		}
+		return res
+	}
+}
+
+// retireOne retires a single session, returning why it was skipped, if it was.
+func retireOne(repoRoot string, s model.Session) string {
+	work, err := git.InspectWork(s.Path, s.Branch, mergedHead(s))
+	if err != nil {
+		return err.Error()
+	}
+	var reasons []string
+	if work.Uncommitted > 0 {
+		reasons = append(reasons, fmt.Sprintf("%d uncommitted file(s)", work.Uncommitted))
+	}
+	if work.Unpushed > 0 {
+		reasons = append(reasons, fmt.Sprintf("%d unpushed commit(s)", work.Unpushed))
+	}
+	if len(reasons) > 0 {
+		return strings.Join(reasons, ", ")
+	}
+
+	if err := tmux.KillSession(s.Slug); err != nil {
+		return err.Error()
+	}
+	if err := git.DeleteWorktree(repoRoot, s.Path, s.Branch, false); err != nil {
+		return err.Error()
+	}
+	// The MR merged and nothing is unpushed, so force is safe even when the
+	// MR was squashed and the branch is not an ancestor of HEAD. DeleteWorktree
+	// may already have removed the branch.
+	if git.BranchExists(repoRoot, s.Branch) {
+		if err := git.DeleteBranch(repoRoot, s.Branch, true); err != nil {
+			return "worktree removed, but " + err.Error()
+		}
+	}
+	return ""
+}
+
+// autoRetireCmd applies the auto-retire policy after a refresh. Sessions that
+// were skipped once are not retried until Deckard restarts, so the summary
+// does not reappear on every refresh.
+func (m *Model) autoRetireCmd() tea.Cmd {
+	if !m.cfg.Retire.Auto || m.state != stateNormal {
+		return nil
+	}
+	var todo []model.Session
+	for _, s := range m.retireCandidates() {
+		if !m.retireSkipped[s.Path] {
+			todo = append(todo, s)
+		}
+	}
+	if len(todo) == 0 {
+		return nil
+	}
+	for _, s := range todo {
+		m.retireSkipped[s.Path] = true
+	}
+	return retireCmd(m.repoRoot, todo, true)
+}
+
+func (m Model) updateRetireConfirm(msg tea.Msg) (tea.Model, tea.Cmd) {
+	if msg, ok := msg.(tea.KeyMsg); ok {
+		switch msg.String() {
+		case "esc", "n", "N":
+			m.state = stateNormal
+			return m, nil
+		case "enter", "y", "Y":
+			m.state = stateNormal
+			m.setNotice("retiring merged worktrees…", false)
+			return m, retireCmd(m.repoRoot, m.retireCandidates(), false)
+		}
+	}
+	return m, nil
+}
+
+func (m Model) updateRetireSummary(msg tea.Msg) (tea.Model, tea.Cmd) {
+	if _, ok := msg.(tea.KeyMsg); ok {
+		m.state = stateNormal
+		m.retireResult = nil
+	}
+	return m, nil
+}
+
+func (m Model) renderRetireConfirmOver(base string) string {
+	var b strings.Builder
+	b.WriteString(errStyle.Render("RETIRE MERGED WORKTREES") + "\n\n")
+	for _, s := range m.retireCandidates() {
+		b.WriteString(okStyle.Render("◆ ") + s.Slug + dimStyle.Render(fmt.Sprintf("  !%d", s.MR.IID)) + "\n")
+	}
+	b.WriteString("\nEach worktree is checked for uncommitted and unpushed work\n" +
+		"first. Clean ones have their tmux session killed, worktree\n" +
+		"removed and branch deleted.\n")
+	b.WriteString("\n" + dimStyle.Render("y/Enter to confirm · Esc/n to cancel"))
+
+	modal := deleteModalStyle.Render(b.String())
+	return lipgloss.Place(m.width, m.height, lipgloss.Center, lipgloss.Center, modal,
+		lipgloss.WithWhitespaceBackground(lipgloss.Color("0")),
+	)
+}
+
+func (m Model) renderRetireSummaryOver(base string) string {
+	r := m.retireResult
+	var b strings.Builder
+	title := "RETIRE SUMMARY"
+	if r.auto {
+		title = "AUTO-RETIRE SUMMARY"
+	}
+	b.WriteString(detailHeadStyle.Render(title) + "\n\n")
+	if len(r.removed) > 0 {
+		b.WriteString(labelStyle.Render("REMOVED") + "\n")
+		for _, slug := range r.removed {
+			b.WriteString(okStyle.Render("◆ ") + slug + "\n")
+		}
+		b.WriteString("\n")
+	}
+	if len(r.skipped) > 0 {
+		b.WriteString(labelStyle.Render("SKIPPED") + "\n")
+		for _, sk := range r.skipped {
+			b.WriteString(warnStyle.Render("▲ ") + sk.slug + "\n")
+			b.WriteString(dimStyle.Render("  "+sk.reason) + "\n")
+		}
+		b.WriteString("\n")
+	}
+	b.WriteString(dimStyle.Render("any key to close"))
+
+	modal := modalStyle.Render(b.String())
+	return lipgloss.Place(m.width, m.height, lipgloss.Center, lipgloss.Center, modal,
+		lipgloss.WithWhitespaceBackground(lipgloss.Color("0")),
+	)
+}
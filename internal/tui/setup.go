@@ -0,0 +1,118 @@
+package tui
+
+import (
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+
+	"deckard/internal/config"
+	"deckard/internal/model"
+	"deckard/internal/setup"
+)
+
+// setupLogLines is how many progress lines the setup modal keeps on screen.
+const setupLogLines = 12
+
+type setupProgressMsg struct {
+	line string
+}
+
+type setupDoneMsg struct {
+	err error
+}
+
+// runSetupCmd runs the setup steps in the background and streams progress
+// back through ch; waitSetupCmd delivers one message at a time.
+func runSetupCmd(ch chan tea.Msg, repoRoot string, s model.Session, cfg config.Setup, env []string) tea.Cmd {
+	go func() {
+		err := setup.Run(repoRoot, s.Path, cfg, env, func(line string) {
+			ch <- setupProgressMsg{line: line}
+		})
+		ch <- setupDoneMsg{err: err}
+	}()
+	return waitSetupCmd(ch)
+}
+
+func waitSetupCmd(ch chan tea.Msg) tea.Cmd {
+	return func() tea.Msg { return <-ch }
+}
+
+// startSetup reserves ports for a freshly created worktree, then shows the
+// setup modal, or goes straight to the agent when there is nothing to set up.
+func (m Model) startSetup(s model.Session) (Model, tea.Cmd) {
+	m.reservePorts(s.Path)
+	if m.cfg.Setup.Empty() {
+		return m.startAgent(s)
+	}
+	m.state = stateSetup
+	m.setupSession = s
+	m.setupLog = nil
+	m.setupErr = ""
+	m.setupDone = false
+	m.setupCh = make(chan tea.Msg)
+	return m, runSetupCmd(m.setupCh, m.repoRoot, s, m.cfg.Setup, m.portEnv(s.Path))
+}
+
+func (m Model) handleSetupMsg(msg tea.Msg) (Model, tea.Cmd, bool) {
+	switch msg := msg.(type) {
+	case setupProgressMsg:
+		m.setupLog = append(m.setupLog, msg.line)
+		if len(m.setupLog) > setupLogLines {
+			m.setupLog = m.setupLog[len(m.setupLog)-setupLogLines:]
+		}
+		return m, waitSetupCmd(m.setupCh), true
+
+	case setupDoneMsg:
+		m.setupDone = true
+		if msg.err != nil {
+			// Keep the worktree; let the user decide whether to carry on.
+			m.setupErr = msg.err.Error()
+			return m, nil, true
+		}
+		sm, cmd := m.startAgent(m.setupSession)
+		return sm, cmd, true
+	}
+	return m, nil, false
+}
+
+func (m Model) updateSetup(msg tea.Msg) (tea.Model, tea.Cmd) {
+	key, ok := msg.(tea.KeyMsg)
+	if !ok || !m.setupDone {
+		return m, nil
+	}
+	switch key.String() {
+	case "enter":
+		return m.startAgent(m.setupSession)
+	case "esc":
+		m.state = stateNormal
+		m.pendingLaunch = launch{attach: true}
+		m.loading = true
+		return m, m.fetchSessions()
+	}
+	return m, nil
+}
+
+func (m Model) renderSetupOver(base string) string {
+	var b strings.Builder
+	b.WriteString(detailHeadStyle.Render("SETTING UP") + "  " + dimStyle.Render(strings.ToUpper(m.setupSession.Slug)) + "\n\n")
+	for _, l := range m.setupLog {
+		if len([]rune(l)) > 52 {
+			l = string([]rune(l)[:51]) + "…"
+		}
+		b.WriteString(dimStyle.Render(l) + "\n")
+	}
+	switch {
+	case !m.setupDone:
+		b.WriteString("\n" + warnStyle.Render(spinnerFrames[m.spinnerFrame]+" running setup…"))
+	case m.setupErr != "":
+		b.WriteString("\n" + errStyle.Render("✕ SETUP FAILED") + "\n")
+		b.WriteString(errStyle.Render(m.setupErr) + "\n")
+		b.WriteString("\n" + dimStyle.Render("the worktree was kept · Enter start claude anyway · Esc back"))
+	}
+
+	modal := modalStyle.Render(b.String())
+	return lipgloss.Place(m.width, m.height, lipgloss.Center, lipgloss.Center, modal,
+		lipgloss.WithWhitespaceBackground(lipgloss.Color("0")),
+	)
+}
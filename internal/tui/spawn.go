@@ -0,0 +1,124 @@
+package tui
+
+import (
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+
+	"deckard/internal/config"
+	"deckard/internal/spawn"
+)
+
+type spawnDoneMsg struct {
+	results []spawn.Result
+	err     error
+}
+
+func spawnCmd(repoRoot string, cfg config.Config, path string) tea.Cmd {
+	return func() tea.Msg {
+		tasks, err := spawn.LoadTasks(path)
+		if err != nil {
+			return spawnDoneMsg{err: err}
+		}
+		return spawnDoneMsg{results: spawn.Run(repoRoot, cfg, tasks, cfg.Spawn.Concurrency, nil)}
+	}
+}
+
+func spawnTasksCmd(repoRoot string, cfg config.Config, tasks []spawn.Task) tea.Cmd {
+	return func() tea.Msg {
+		return spawnDoneMsg{results: spawn.Run(repoRoot, cfg, tasks, cfg.Spawn.Concurrency, nil)}
+	}
+}
+
+func (m Model) updateSpawnPath(msg tea.Msg) (tea.Model, tea.Cmd) {
+	if key, ok := msg.(tea.KeyMsg); ok {
+		switch key.String() {
+		case "esc":
+			m.state = stateNormal
+			m.inputErr = ""
+			m.nameInput.Blur()
+			return m, nil
+		case "enter":
+			path := strings.TrimSpace(m.nameInput.Value())
+			if path == "" {
+				m.inputErr = "path cannot be empty"
+				return m, nil
+			}
+			m.inputErr = ""
+			m.spawnRunning = true
+			return m, spawnCmd(m.repoRoot, m.cfg, path)
+		}
+	}
+	if m.spawnRunning {
+		return m, nil
+	}
+	var cmd tea.Cmd
+	m.nameInput, cmd = m.nameInput.Update(msg)
+	return m, cmd
+}
+
+func (m Model) updateSpawnReport(msg tea.Msg) (tea.Model, tea.Cmd) {
+	if _, ok := msg.(tea.KeyMsg); ok {
+		m.state = stateNormal
+		m.spawnResults = nil
+	}
+	return m, nil
+}
+
+func (m Model) renderSpawnPathOver(base string) string {
+	var b strings.Builder
+	b.WriteString(detailHeadStyle.Render("IMPORT TASKS") + "\n\n")
+	b.WriteString(labelStyle.Render("TASK FILE") + "\n")
+	b.WriteString(m.nameInput.View() + "\n")
+	if m.inputErr != "" {
+		b.WriteString("\n" + errStyle.Render(m.inputErr) + "\n")
+	}
+	if m.spawnRunning {
+		b.WriteString("\n" + warnStyle.Render(spinnerFrames[m.spinnerFrame]+" creating sessions…"))
+	} else {
+		b.WriteString("\n" + dimStyle.Render("YAML list of branch, base, agent, prompt"))
+	}
+
+	modal := modalStyle.Render(b.String())
+	return lipgloss.Place(m.width, m.height, lipgloss.Center, lipgloss.Center, modal,
+		lipgloss.WithWhitespaceBackground(lipgloss.Color("0")),
+	)
+}
+
+func (m Model) renderSpawnReportOver(base string) string {
+	var b strings.Builder
+	b.WriteString(detailHeadStyle.Render("SPAWN REPORT") + "\n\n")
+	var started, failed []spawn.Result
+	for _, r := range m.spawnResults {
+		if r.Started() {
+			started = append(started, r)
+		} else {
+			failed = append(failed, r)
+		}
+	}
+	if len(started) > 0 {
+		b.WriteString(labelStyle.Render("STARTED") + "\n")
+		for _, r := range started {
+			b.WriteString(okStyle.Render("◆ ") + r.Slug + "\n")
+			if r.Stale != nil {
+				b.WriteString(dimStyle.Render("  stale base: "+r.Stale.Error()) + "\n")
+			}
+		}
+		b.WriteString("\n")
+	}
+	if len(failed) > 0 {
+		b.WriteString(labelStyle.Render("FAILED") + "\n")
+		for _, r := range failed {
+			b.WriteString(errStyle.Render("✕ ") + r.Slug + "\n")
+			b.WriteString(dimStyle.Render("  "+r.Err.Error()) + "\n")
+		}
+		b.WriteString("\n")
+	}
+	b.WriteString(dimStyle.Render("any key to close"))
+
+	modal := modalStyle.Render(b.String())
+	return lipgloss.Place(m.width, m.height, lipgloss.Center, lipgloss.Center, modal,
+		lipgloss.WithWhitespaceBackground(lipgloss.Color("0")),
+	)
+}
@@ -0,0 +1,249 @@
+package tui
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+
+	"deckard/internal/model"
+	"deckard/internal/store"
+)
+
+// applyStages sets the lifecycle stage of every session: the manual override
+// if one still applies, otherwise the derived stage. Overrides that no longer
+// apply, or whose worktree is gone, are dropped.
+func (m *Model) applyStages() {
+	live := map[string]bool{}
+	changed := false
+	for i := range m.sessions {
+		s := &m.sessions[i]
+		live[s.Path] = true
+		derived := model.DeriveStage(*s)
+		s.Stage, s.StageManual = derived, false
+		if m.store == nil {
+			continue
+		}
+		o, ok := m.store.Stages[s.Path]
+		switch {
+		case !ok:
+		case o.Derived == string(derived):
+			s.Stage, s.StageManual = model.Stage(o.Stage), true
+		default:
+			delete(m.store.Stages, s.Path)
+			changed = true
+		}
+	}
+	if m.store == nil {
+		return
+	}
+	for path := range m.store.Stages {
+		if !live[path] {
+			delete(m.store.Stages, path)
+			changed = true
+		}
+	}
+	if changed {
+		if err := m.store.Save(); err != nil {
+			m.setNotice(err.Error(), true)
+		}
+	}
+}
+
+// setStage overrides the stage of s; an empty stage goes back to deriving it.
+func (m *Model) setStage(s model.Session, st model.Stage) {
+	if m.store == nil {
+		m.setNotice("stage overrides need the data dir", true)
+		return
+	}
+	if st == "" {
+		delete(m.store.Stages, s.Path)
+	} else {
+		m.store.Stages[s.Path] = store.StageOverride{
+			Stage:   string(st),
+			Derived: string(model.DeriveStage(s)),
+			SetAt:   time.Now(),
+		}
+	}
+	if err := m.store.Save(); err != nil {
+		m.setNotice(err.Error(), true)
+	}
+	m.applyStages()
+	m.buildItems()
+}
+
+func stageLabel(st model.Stage) string {
+	switch st {
+	case model.StagePlanning:
+		return dimStyle.Render("· PLANNING")
+	case model.StageWorking:
+		return okStyle.Render("~ WORKING")
+	case model.StageNeedsInput:
+		return warnStyle.Render("▲ NEEDS INPUT")
+	case model.StageInReview:
+		return warnStyle.Render("◇ IN REVIEW")
+	case model.StageCIFailing:
+		return errStyle.Render("✕ CI FAILING")
+	case model.StageReady:
+		return okStyle.Render("◆ READY TO MERGE")
+	case model.StageMerged:
+		return dimStyle.Render("◆ MERGED")
+	default:
+		return dimStyle.Render("─")
+	}
+}
+
+// — stage modal —————————————————————————————————————————————————————————————
+
+func (m Model) updateStage(msg tea.Msg) (tea.Model, tea.Cmd) {
+	key, ok := msg.(tea.KeyMsg)
+	if !ok {
+		return m, nil
+	}
+	s := m.selectedSession()
+	if s == nil {
+		m.state = stateNormal
+		return m, nil
+	}
+	k := key.String()
+	switch {
+	case k == "esc":
+		m.state = stateNormal
+	case k == "0":
+		m.setStage(*s, "")
+		m.state = stateNormal
+	case len(k) == 1 && k[0] >= '1' && int(k[0]-'1') < len(model.Stages):
+		m.setStage(*s, model.Stages[k[0]-'1'])
+		m.state = stateNormal
+	}
+	return m, nil
+}
+
+func (m Model) renderStageOver(base string) string {
+	s := m.selectedSession()
+	if s == nil {
+		return base
+	}
+	var b strings.Builder
+	b.WriteString(detailHeadStyle.Render("SET STAGE") + "  " + dimStyle.Render(strings.ToUpper(s.Slug)) + "\n\n")
+	for i, st := range model.Stages {
+		line := fmt.Sprintf("%s  %s", labelStyle.Render(fmt.Sprintf("%d", i+1)), stageLabel(st))
+		if st == s.Stage {
+			line += dimStyle.Render("  current")
+		}
+		b.WriteString(line + "\n")
+	}
+	b.WriteString("\n" + labelStyle.Render("0") + "  automatic" + dimStyle.Render(" — "+string(model.DeriveStage(*s))) + "\n")
+	b.WriteString("\n" + dimStyle.Render("an override holds until the derived stage changes"))
+
+	modal := modalStyle.Render(b.String())
+	return lipgloss.Place(m.width, m.height, lipgloss.Center, lipgloss.Center, modal,
+		lipgloss.WithWhitespaceBackground(lipgloss.Color("0")),
+	)
+}
+
+// — board ———————————————————————————————————————————————————————————————————
+
+// boardColumns returns the list positions of the visible sessions for each
+// stage, in model.Stages order.
+func (m Model) boardColumns() [][]int {
+	cols := make([][]int, len(model.Stages))
+	for pos, r := range m.rows {
+		for c, st := range model.Stages {
+			if r.session >= 0 && m.sessions[r.session].Stage == st {
+				cols[c] = append(cols[c], pos)
+			}
+		}
+	}
+	return cols
+}
+
+// boardPosition returns the column and row of the selected session.
+func (m Model) boardPosition(cols [][]int) (col, row int) {
+	sel := m.list.Index()
+	for c, idxs := range cols {
+		for r, pos := range idxs {
+			if pos == sel {
+				return c, r
+			}
+		}
+	}
+	return 0, 0
+}
+
+// moveBoard moves the selection on the board; columns without sessions are
+// skipped.
+func (m *Model) moveBoard(dCol, dRow int) {
+	cols := m.boardColumns()
+	col, row := m.boardPosition(cols)
+	if dRow != 0 {
+		row += dRow
+		if row >= 0 && row < len(cols[col]) {
+			m.list.Select(cols[col][row])
+		}
+		return
+	}
+	for c := col + dCol; c >= 0 && c < len(cols); c += dCol {
+		if len(cols[c]) == 0 {
+			continue
+		}
+		m.list.Select(cols[c][min(row, len(cols[c])-1)])
+		return
+	}
+}
+
+func (m Model) renderBoard() string {
+	cols := m.boardColumns()
+	width := m.width / len(cols)
+	height := m.height - 2
+	sel := m.list.Index()
+
+	var rendered []string
+	for c, idxs := range cols {
+		var b strings.Builder
+		b.WriteString(stageLabel(model.Stages[c]) + dimStyle.Render(fmt.Sprintf(" %d", len(idxs))) + "\n\n")
+		for _, pos := range idxs {
+			s := m.sessions[m.rows[pos].session]
+			name := s.Slug
+			if limit := width - 5; limit > 0 && len([]rune(name)) > limit {
+				name = string([]rune(name)[:limit-1]) + "…"
+			}
+			if s.StageManual {
+				name += dimStyle.Render("*")
+			}
+			if pos == sel {
+				b.WriteString(labelStyle.Render("▌") + boldStyle.Render(name) + "\n")
+			} else {
+				b.WriteString(" " + name + "\n")
+			}
+			b.WriteString(dimStyle.Render(" "+boardCardDetail(s)) + "\n\n")
+		}
+		style := lipgloss.NewStyle().Width(width - 1).Height(height).PaddingLeft(1)
+		if c > 0 {
+			style = style.Border(lipgloss.NormalBorder(), false, false, false, true).
+				BorderForeground(lipgloss.Color("238")).Width(width - 2)
+		}
+		rendered = append(rendered, style.Render(b.String()))
+	}
+	return lipgloss.JoinHorizontal(lipgloss.Top, rendered...)
+}
+
+// boardCardDetail is the one-line summary under a card's name.
+func boardCardDetail(s model.Session) string {
+	var parts []string
+	if s.MR != nil {
+		parts = append(parts, fmt.Sprintf("!%d", s.MR.IID))
+	}
+	if s.Ahead > 0 {
+		parts = append(parts, fmt.Sprintf("↑%d", s.Ahead))
+	}
+	if s.TmuxRunning {
+		parts = append(parts, "agent")
+	}
+	if len(parts) == 0 {
+		return "─"
+	}
+	return strings.Join(parts, " · ")
+}
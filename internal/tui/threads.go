@@ -0,0 +1,289 @@
+package tui
+
+import (
+	"fmt"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+
+	"deckard/internal/gitlab"
+	"deckard/internal/model"
+	"deckard/internal/prompt"
+	"deckard/internal/tmux"
+)
+
+// — thread messages —————————————————————————————————————————————————————————
+
+type threadsLoadedMsg struct {
+	threads []model.Thread
+	err     error
+}
+
+type reviewSentMsg struct {
+	slug    string
+	threads int
+	err     error
+}
+
+type threadsResolvedMsg struct {
+	resolved int
+	err      error
+}
+
+// — thread commands —————————————————————————————————————————————————————————
+
+func fetchThreadsCmd(iid int) tea.Cmd {
+	return func() tea.Msg {
+		threads, err := gitlab.FetchUnresolvedThreads(iid)
+		return threadsLoadedMsg{threads: threads, err: err}
+	}
+}
+
+// addressReviewCmd delivers the given threads to the session's agent, fetching
+// them first when threads is nil. The agent is started with opts if it is not
+// running.
+func addressReviewCmd(s model.Session, opts tmux.Options, threads []model.Thread) tea.Cmd {
+	return func() tea.Msg {
+		if threads == nil {
+			var err error
+			threads, err = gitlab.FetchUnresolvedThreads(s.MR.IID)
+			if err != nil {
+				return reviewSentMsg{slug: s.Slug, err: err}
+			}
+		}
+		if len(threads) == 0 {
+			return reviewSentMsg{slug: s.Slug, err: fmt.Errorf("no unresolved threads on !%d", s.MR.IID)}
+		}
+		if err := tmux.Deliver(s.Slug, s.Path, prompt.Review(s.Branch, s.MR, threads), opts); err != nil {
+			return reviewSentMsg{slug: s.Slug, err: err}
+		}
+		return reviewSentMsg{slug: s.Slug, threads: len(threads)}
+	}
+}
+
+func resolveThreadsCmd(iid int, ids []string) tea.Cmd {
+	return func() tea.Msg {
+		for i, id := range ids {
+			if err := gitlab.ResolveThread(iid, id); err != nil {
+				return threadsResolvedMsg{resolved: i, err: err}
+			}
+		}
+		return threadsResolvedMsg{resolved: len(ids)}
+	}
+}
+
+// — thread state ————————————————————————————————————————————————————————————
+
+func (m Model) openThreads(s *model.Session) (Model, tea.Cmd) {
+	m.state = stateThreads
+	m.threads = nil
+	m.threadsLoading = true
+	m.threadsErr = ""
+	m.threadsNote = ""
+	m.threadCursor = 0
+	m.threadMarked = map[string]bool{}
+	return m, fetchThreadsCmd(s.MR.IID)
+}
+
+func (m Model) handleThreadsMsg(msg tea.Msg) (Model, tea.Cmd, bool) {
+	switch msg := msg.(type) {
+	case threadsLoadedMsg:
+		m.threadsLoading = false
+		if msg.err != nil {
+			m.threadsErr = msg.err.Error()
+			return m, nil, true
+		}
+		m.threadsErr = ""
+		m.threads = msg.threads
+		if m.threadCursor >= len(m.threads) {
+			m.threadCursor = 0
+		}
+		return m, nil, true
+
+	case reviewSentMsg:
+		text := fmt.Sprintf("sent %d thread(s) to %s", msg.threads, msg.slug)
+		if msg.err != nil {
+			text = "address review (" + msg.slug + "): " + msg.err.Error()
+		}
+		if m.state == stateThreads {
+			if msg.err != nil {
+				m.threadsErr = text
+			} else {
+				m.threadsNote = text
+			}
+			return m, nil, true
+		}
+		m.setNotice(text, msg.err != nil)
+		return m, nil, true
+
+	case threadsResolvedMsg:
+		m.threadMarked = map[string]bool{}
+		if msg.err != nil {
+			m.threadsErr = fmt.Sprintf("resolved %d, then: %v", msg.resolved, msg.err)
+		} else {
+			m.threadsNote = fmt.Sprintf("resolved %d thread(s)", msg.resolved)
+		}
+		s := m.selectedSession()
+		if s == nil || s.MR == nil {
+			return m, nil, true
+		}
+		m.threadsLoading = true
+		return m, fetchThreadsCmd(s.MR.IID), true
+	}
+	return m, nil, false
+}
+
+func (m Model) updateThreads(msg tea.Msg) (tea.Model, tea.Cmd) {
+	key, ok := msg.(tea.KeyMsg)
+	if !ok {
+		return m, nil
+	}
+	// Leaving must work even if a refresh took the session or its MR away.
+	if k := key.String(); k == "esc" || k == "q" {
+		m.state = stateNormal
+		return m, nil
+	}
+	s := m.selectedSession()
+	if s == nil || s.MR == nil {
+		return m, nil
+	}
+	switch key.String() {
+	case "up", "k":
+		if m.threadCursor > 0 {
+			m.threadCursor--
+		}
+	case "down", "j":
+		if m.threadCursor < len(m.threads)-1 {
+			m.threadCursor++
+		}
+	case " ":
+		if m.threadCursor < len(m.threads) {
+			id := m.threads[m.threadCursor].ID
+			m.threadMarked[id] = !m.threadMarked[id]
+		}
+	case "a":
+		if len(m.threads) > 0 {
+			m.threadsNote = "sending to " + s.Slug + "…"
+			return m, addressReviewCmd(*s, m.agentOptions(*s), m.threads)
+		}
+	case "R":
+		// Resolve marked threads, or the selected one if none are marked.
+		var ids []string
+		for _, t := range m.threads {
+			if m.threadMarked[t.ID] {
+				ids = append(ids, t.ID)
+			}
+		}
+		if len(ids) == 0 && m.threadCursor < len(m.threads) {
+			ids = []string{m.threads[m.threadCursor].ID}
+		}
+		if len(ids) > 0 {
+			m.threadsNote = fmt.Sprintf("resolving %d thread(s)…", len(ids))
+			return m, resolveThreadsCmd(s.MR.IID, ids)
+		}
+	case "f":
+		m.threadsLoading = true
+		return m, fetchThreadsCmd(s.MR.IID)
+	case "o":
+		if s.MR.WebURL != "" {
+			return m, openURLCmd(s.MR.WebURL)
+		}
+	}
+	return m, nil
+}
+
+// — thread rendering ————————————————————————————————————————————————————————
+
+func (m Model) renderThreads() string {
+	s := m.selectedSession()
+	var head strings.Builder
+	head.WriteString(detailHeadStyle.Render("THREADS"))
+	if s != nil {
+		head.WriteString("  " + dimStyle.Render(strings.ToUpper(s.Slug)))
+		if s.MR != nil {
+			head.WriteString(fmt.Sprintf("  !%d", s.MR.IID))
+		}
+	}
+	if m.threadsNote != "" {
+		head.WriteString("  " + okStyle.Render(m.threadsNote))
+	}
+	if m.threadsErr != "" {
+		head.WriteString("  " + errStyle.Render(m.threadsErr))
+	}
+
+	lw := m.width / 3
+	dw := m.width - lw
+	h := m.height - 5
+
+	listPane := lipgloss.NewStyle().
+		Width(lw).
+		Height(h).
+		PaddingLeft(2).
+		Render(m.renderThreadList(lw - 2))
+
+	bodyPane := lipgloss.NewStyle().
+		Border(lipgloss.NormalBorder(), false, false, false, true).
+		BorderForeground(lipgloss.Color("86")).
+		PaddingLeft(3).
+		PaddingRight(2).
+		Width(dw - 1).
+		Height(h).
+		Render(m.renderThreadBody())
+
+	return lipgloss.JoinVertical(lipgloss.Left,
+		lipgloss.NewStyle().Padding(1, 2, 0, 2).Render(head.String()),
+		"",
+		lipgloss.JoinHorizontal(lipgloss.Top, listPane, bodyPane),
+		m.renderHelp(),
+	)
+}
+
+func (m Model) renderThreadList(width int) string {
+	if m.threadsLoading {
+		return dimStyle.Render("LOADING THREADS…")
+	}
+	if len(m.threads) == 0 {
+		return okStyle.Render("◆ ALL THREADS RESOLVED")
+	}
+	var b strings.Builder
+	for i, t := range m.threads {
+		mark := dimStyle.Render("○")
+		if m.threadMarked[t.ID] {
+			mark = okStyle.Render("●")
+		}
+		where := "general"
+		if t.File != "" {
+			where = fmt.Sprintf("%s:%d", t.File, t.Line)
+		}
+		if limit := width - 4; limit > 0 && len([]rune(where)) > limit {
+			where = "…" + string([]rune(where)[len([]rune(where))-limit+1:])
+		}
+		line := mark + " " + where
+		if i == m.threadCursor {
+			line = labelStyle.Render("▌") + boldStyle.Render(line)
+		} else {
+			line = " " + line
+		}
+		b.WriteString(line + "\n")
+		b.WriteString(dimStyle.Render("    @"+t.Author) + "\n")
+	}
+	return b.String()
+}
+
+func (m Model) renderThreadBody() string {
+	if m.threadCursor >= len(m.threads) {
+		return ""
+	}
+	t := m.threads[m.threadCursor]
+	var b strings.Builder
+	if t.File != "" {
+		b.WriteString(labelStyle.Render("FILE     ") + fmt.Sprintf("%s:%d", t.File, t.Line) + "\n")
+	}
+	b.WriteString(labelStyle.Render("AUTHOR   ") + "@" + t.Author + "\n\n")
+	b.WriteString(t.Body + "\n")
+	for _, n := range t.Notes {
+		b.WriteString("\n" + labelStyle.Render("@"+n.Author) + "\n" + n.Body + "\n")
+	}
+	return b.String()
+}
@@ -0,0 +1,305 @@
+package tui
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+
+	"deckard/internal/git"
+	"deckard/internal/model"
+	"deckard/internal/store"
+	"deckard/internal/tmux"
+)
+
+// — trash messages ——————————————————————————————————————————————————————————
+
+type archivedMsg struct {
+	entry store.TrashEntry
+	err   error
+}
+
+type restoredMsg struct {
+	id   string
+	slug string
+	err  error
+}
+
+type trashPurgedMsg struct {
+	ids []string
+	err error
+}
+
+// — trash commands ——————————————————————————————————————————————————————————
+
+func trashRef(id, name string) string {
+	return git.TrashRefPrefix + id + "/" + name
+}
+
+// archiveCmd moves a worktree to the trash (see archive).
+func archiveCmd(repoRoot string, s model.Session) tea.Cmd {
+	return func() tea.Msg {
+		e, err := archive(repoRoot, s)
+		return archivedMsg{entry: e, err: err}
+	}
+}
+
+// archive keeps the branch tip and a snapshot of any uncommitted changes
+// under refs/deckard/trash/<id>/, then removes the tmux session, worktree and
+// branch. The entry is returned once the refs exist, even if removal fails,
+// so the refs left behind can be reported.
+func archive(repoRoot string, s model.Session) (store.TrashEntry, error) {
+	e := store.TrashEntry{
+		ID:         time.Now().Format("20060102-150405") + "-" + s.Slug,
+		Slug:       s.Slug,
+		Branch:     s.Branch,
+		Path:       s.Path,
+		ArchivedAt: time.Now(),
+	}
+	if s.MR != nil {
+		e.MRIID = s.MR.IID
+	}
+
+	var err error
+	if e.Tip, err = git.HeadCommit(s.Path); err != nil {
+		return store.TrashEntry{}, err
+	}
+	if e.Snapshot, err = git.Snapshot(s.Path); err != nil {
+		return store.TrashEntry{}, err
+	}
+	if err := git.SetRef(repoRoot, trashRef(e.ID, "tip"), e.Tip); err != nil {
+		return store.TrashEntry{}, err
+	}
+	if e.Snapshot != "" {
+		if err := git.SetRef(repoRoot, trashRef(e.ID, "snapshot"), e.Snapshot); err != nil {
+			return store.TrashEntry{}, err
+		}
+	}
+
+	// Everything is safe under the trash refs from here on, so the removal
+	// can be forced.
+	if err := tmux.KillSession(s.Slug); err != nil {
+		return e, err
+	}
+	if err := git.DeleteWorktree(repoRoot, s.Path, s.Branch, true); err != nil {
+		return e, err
+	}
+	return e, nil
+}
+
+func restoreCmd(repoRoot string, e store.TrashEntry) tea.Cmd {
+	return func() tea.Msg {
+		if err := git.RestoreWorktree(repoRoot, e.Path, e.Branch, e.Tip, e.Snapshot); err != nil {
+			return restoredMsg{id: e.ID, slug: e.Slug, err: err}
+		}
+		git.DeleteRef(repoRoot, trashRef(e.ID, "tip"))
+		git.DeleteRef(repoRoot, trashRef(e.ID, "snapshot"))
+		return restoredMsg{id: e.ID, slug: e.Slug}
+	}
+}
+
+func purgeTrashCmd(repoRoot string, ids []string) tea.Cmd {
+	return func() tea.Msg {
+		for i, id := range ids {
+			for _, name := range []string{"tip", "snapshot"} {
+				if err := git.DeleteRef(repoRoot, trashRef(id, name)); err != nil {
+					return trashPurgedMsg{ids: ids[:i], err: err}
+				}
+			}
+		}
+		return trashPurgedMsg{ids: ids}
+	}
+}
+
+// expiredTrash returns the IDs of archives older than the retention period.
+func (m Model) expiredTrash() []string {
+	if m.store == nil || m.cfg.Trash.RetentionDays <= 0 {
+		return nil
+	}
+	cutoff := time.Now().AddDate(0, 0, -m.cfg.Trash.RetentionDays)
+	var ids []string
+	for _, e := range m.store.Trash {
+		if e.ArchivedAt.Before(cutoff) {
+			ids = append(ids, e.ID)
+		}
+	}
+	return ids
+}
+
+// — trash state —————————————————————————————————————————————————————————————
+
+func (m *Model) removeTrashEntries(ids ...string) {
+	drop := map[string]bool{}
+	for _, id := range ids {
+		drop[id] = true
+	}
+	kept := m.store.Trash[:0]
+	for _, e := range m.store.Trash {
+		if !drop[e.ID] {
+			kept = append(kept, e)
+		}
+	}
+	m.store.Trash = kept
+	if m.trashCursor >= len(kept) && m.trashCursor > 0 {
+		m.trashCursor = len(kept) - 1
+	}
+	if err := m.store.Save(); err != nil {
+		m.setNotice(err.Error(), true)
+	}
+}
+
+// takeMeta removes and returns the stored metadata of the worktree at path,
+// so it can travel into the trash with it.
+func (m *Model) takeMeta(path string) *model.Meta {
+	meta, ok := m.store.Meta[path]
+	if !ok {
+		return nil
+	}
+	delete(m.store.Meta, path)
+	return &meta
+}
+
+func (m Model) handleTrashMsg(msg tea.Msg) (Model, tea.Cmd, bool) {
+	switch msg := msg.(type) {
+	case archivedMsg:
+		m.state = stateNormal
+		m.inputErr = ""
+		switch {
+		case msg.err != nil && msg.entry.ID != "":
+			// The worktree is still there, so it stays out of the trash and
+			// keeps its metadata; only the refs need cleaning up.
+			m.setNotice("archive: "+msg.err.Error()+" — "+git.TrashRefPrefix+msg.entry.ID+"/ left behind", true)
+		case msg.err != nil:
+			m.setNotice("archive: "+msg.err.Error(), true)
+		default:
+			m.setNotice("archived "+msg.entry.Slug+" to trash — T to restore", false)
+			if m.store != nil {
+				msg.entry.Meta = m.takeMeta(msg.entry.Path)
+				m.store.Trash = append(m.store.Trash, msg.entry)
+				if err := m.store.Save(); err != nil {
+					m.setNotice(err.Error(), true)
+				}
+			}
+		}
+		m.loading = true
+		return m, m.fetchSessions(), true
+
+	case restoredMsg:
+		if msg.err != nil {
+			m.trashErr = msg.err.Error()
+			return m, nil, true
+		}
+		for _, e := range m.store.Trash {
+			if e.ID == msg.id && e.Meta != nil {
+				m.store.Meta[e.Path] = *e.Meta
+			}
+		}
+		m.removeTrashEntries(msg.id)
+		m.state = stateNormal
+		m.setNotice("restored "+msg.slug, false)
+		m.loading = true
+		return m, m.fetchSessions(), true
+
+	case trashPurgedMsg:
+		if m.store != nil {
+			m.removeTrashEntries(msg.ids...)
+		}
+		if msg.err != nil {
+			m.trashErr = msg.err.Error()
+		}
+		return m, nil, true
+	}
+	return m, nil, false
+}
+
+func (m Model) selectedTrash() *store.TrashEntry {
+	if m.store == nil || m.trashCursor < 0 || m.trashCursor >= len(m.store.Trash) {
+		return nil
+	}
+	return &m.store.Trash[m.trashCursor]
+}
+
+func (m Model) updateTrash(msg tea.Msg) (tea.Model, tea.Cmd) {
+	key, ok := msg.(tea.KeyMsg)
+	if !ok {
+		return m, nil
+	}
+	armed := m.trashPurgeArmed
+	m.trashPurgeArmed = false
+	switch key.String() {
+	case "esc", "q":
+		m.state = stateNormal
+	case "up", "k":
+		if m.trashCursor > 0 {
+			m.trashCursor--
+		}
+	case "down", "j":
+		if m.store != nil && m.trashCursor < len(m.store.Trash)-1 {
+			m.trashCursor++
+		}
+	case "enter", "r":
+		if e := m.selectedTrash(); e != nil {
+			m.trashErr = ""
+			return m, restoreCmd(m.repoRoot, *e)
+		}
+	case "x":
+		if e := m.selectedTrash(); e != nil {
+			if !armed {
+				m.trashPurgeArmed = true
+				return m, nil
+			}
+			return m, purgeTrashCmd(m.repoRoot, []string{e.ID})
+		}
+	}
+	return m, nil
+}
+
+// — trash rendering —————————————————————————————————————————————————————————
+
+func (m Model) renderTrash() string {
+	var head strings.Builder
+	head.WriteString(detailHeadStyle.Render("TRASH"))
+	if m.cfg.Trash.RetentionDays > 0 {
+		head.WriteString("  " + dimStyle.Render(fmt.Sprintf("archives are purged after %d days", m.cfg.Trash.RetentionDays)))
+	}
+	if m.trashErr != "" {
+		head.WriteString("  " + errStyle.Render(m.trashErr))
+	}
+
+	var b strings.Builder
+	if m.store == nil || len(m.store.Trash) == 0 {
+		b.WriteString(dimStyle.Render("TRASH IS EMPTY"))
+	} else {
+		for i, e := range m.store.Trash {
+			line := fmt.Sprintf("%-40s %s", e.Slug, dimStyle.Render(e.ArchivedAt.Format("2006-01-02 15:04")))
+			if e.Snapshot != "" {
+				line += "  " + warnStyle.Render("+ uncommitted changes")
+			}
+			if e.MRIID != 0 {
+				line += "  " + dimStyle.Render(fmt.Sprintf("!%d", e.MRIID))
+			}
+			if i == m.trashCursor {
+				line = labelStyle.Render("▌") + boldStyle.Render(line)
+			} else {
+				line = " " + line
+			}
+			b.WriteString(line + "\n")
+		}
+		if e := m.selectedTrash(); e != nil {
+			b.WriteString("\n" + labelStyle.Render("BRANCH   ") + e.Branch + "\n")
+			b.WriteString(labelStyle.Render("PATH     ") + e.Path + "\n")
+			b.WriteString(labelStyle.Render("REF      ") + trashRef(e.ID, "tip") + "\n")
+			if m.trashPurgeArmed {
+				b.WriteString("\n" + errStyle.Render("▲ press x again to purge permanently") + "\n")
+			}
+		}
+	}
+
+	return lipgloss.JoinVertical(lipgloss.Left,
+		lipgloss.NewStyle().Padding(1, 2, 0, 2).Render(head.String()),
+		lipgloss.NewStyle().Padding(1, 2, 0, 2).Height(m.height-4).Render(b.String()),
+		m.renderHelp(),
+	)
+}
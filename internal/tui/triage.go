@@ -0,0 +1,123 @@
+package tui
+
+import (
+	"fmt"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+
+	"deckard/internal/model"
+)
+
+// triageDelay is how long deckard waits before attaching to the next waiting
+// session, so a key press can stop triage.
+const triageDelay = 1500 * time.Millisecond
+
+// triageGoMsg attaches to the next session in triage once the delay is up.
+type triageGoMsg struct {
+	path string
+}
+
+// waitingQueue returns the sessions that need input, most urgent first.
+func (m Model) waitingQueue() []model.Session {
+	var idxs []int
+	for i, s := range m.sessions {
+		if s.NeedsInput {
+			idxs = append(idxs, i)
+		}
+	}
+	sortSessions(m.sessions, idxs, sortAttention)
+	queue := make([]model.Session, len(idxs))
+	for k, i := range idxs {
+		queue[k] = m.sessions[i]
+	}
+	return queue
+}
+
+// nextWaiting returns the most urgent session needing input that triage has
+// not visited yet.
+func (m Model) nextWaiting() (model.Session, bool) {
+	for _, s := range m.waitingQueue() {
+		if !m.triageSeen[s.Path] {
+			return s, true
+		}
+	}
+	return model.Session{}, false
+}
+
+// attachNext attaches to the most urgent session needing input.
+func (m Model) attachNext() (tea.Model, tea.Cmd) {
+	queue := m.waitingQueue()
+	if len(queue) == 0 {
+		m.setNotice("no sessions need input", false)
+		return m, nil
+	}
+	return m, m.attach(queue[0])
+}
+
+// attach selects s in the list and attaches to its agent.
+func (m *Model) attach(s model.Session) tea.Cmd {
+	for pos, r := range m.rows {
+		if r.session >= 0 && m.sessions[r.session].Path == s.Path {
+			m.list.Select(pos)
+		}
+	}
+	return ensureAndAttachCmd(s, m.launchOptions(s.Path, ""))
+}
+
+// startTriage attaches to each waiting session in turn: after every detach
+// the next one follows, until none is left or a key stops it.
+func (m Model) startTriage() (tea.Model, tea.Cmd) {
+	m.triage = true
+	m.triageSeen = map[string]bool{}
+	s, ok := m.nextWaiting()
+	if !ok {
+		m.triage = false
+		m.setNotice("no sessions need input", false)
+		return m, nil
+	}
+	m.triageSeen[s.Path] = true
+	return m, m.attach(s)
+}
+
+// continueTriage queues the next waiting session after a detach. Sessions
+// already visited are skipped, so one left unanswered doesn't come straight
+// back.
+func (m *Model) continueTriage() tea.Cmd {
+	s, ok := m.nextWaiting()
+	if !ok {
+		m.stopTriage()
+		m.setNotice(fmt.Sprintf("triage done — %d session(s) visited", len(m.triageSeen)), false)
+		return nil
+	}
+	m.triageNext = s.Path
+	m.setNotice(fmt.Sprintf("triage: %s next — any key stops", s.Slug), false)
+	return tea.Tick(triageDelay, func(time.Time) tea.Msg {
+		return triageGoMsg{path: s.Path}
+	})
+}
+
+func (m *Model) stopTriage() {
+	m.triage = false
+	m.triageNext = ""
+}
+
+func (m Model) handleTriageMsg(msg tea.Msg) (Model, tea.Cmd, bool) {
+	gm, ok := msg.(triageGoMsg)
+	if !ok {
+		return m, nil, false
+	}
+	if !m.triage || m.triageNext != gm.path || m.state != stateNormal {
+		return m, nil, true
+	}
+	m.triageNext = ""
+	for _, s := range m.sessions {
+		if s.Path == gm.path {
+			m.triageSeen[s.Path] = true
+			m.notice = ""
+			return m, m.attach(s), true
+		}
+	}
+	// Gone since it was queued; try the one after.
+	return m, m.continueTriage(), true
+}
@@ -0,0 +1,505 @@
+package tui
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+
+	"deckard/internal/claude"
+	"deckard/internal/config"
+	"deckard/internal/git"
+	"deckard/internal/model"
+	"deckard/internal/setup"
+	"deckard/internal/spawn"
+	"deckard/internal/store"
+)
+
+// variantField is the focused input of the new-variants form.
+type variantField int
+
+const (
+	variantName variantField = iota
+	variantBase
+	variantCount
+	variantTask
+)
+
+// maxVariants bounds how many worktrees one variants action creates.
+const maxVariants = 9
+
+// variantStats is what the compare view shows for one variant.
+type variantStats struct {
+	diff     git.DiffStat
+	diffErr  string
+	usage    claude.Usage
+	usageErr string
+}
+
+// testResult is the outcome of the configured test command in one variant.
+type testResult struct {
+	passed   bool
+	duration time.Duration
+	tail     []string // last lines of output
+}
+
+// — variant messages ————————————————————————————————————————————————————————
+
+type variantStatsMsg struct {
+	group string
+	stats map[string]variantStats // by worktree path
+}
+
+type variantTestsMsg struct {
+	group   string
+	results map[string]testResult // by worktree path
+}
+
+type winnerPickedMsg struct {
+	winner   string
+	archived []store.TrashEntry
+	err      error
+}
+
+// — variant commands ————————————————————————————————————————————————————————
+
+func loadVariantStatsCmd(group string, variants []model.Session) tea.Cmd {
+	return func() tea.Msg {
+		stats := make(map[string]variantStats, len(variants))
+		var mu sync.Mutex
+		var wg sync.WaitGroup
+		for _, s := range variants {
+			wg.Add(1)
+			go func(s model.Session) {
+				defer wg.Done()
+				var st variantStats
+				var err error
+				if s.Base == "" {
+					st.diffErr = "no recorded base"
+				} else if st.diff, err = git.DiffAgainst(s.Path, s.Base); err != nil {
+					st.diffErr = err.Error()
+				}
+				if st.usage, err = claude.UsageFor(s.Path); err != nil {
+					st.usageErr = err.Error()
+				}
+				mu.Lock()
+				stats[s.Path] = st
+				mu.Unlock()
+			}(s)
+		}
+		wg.Wait()
+		return variantStatsMsg{group: group, stats: stats}
+	}
+}
+
+// testVariantsCmd runs the test command in every variant at once, each with
+// its port variables from env, keyed by path.
+func testVariantsCmd(group string, variants []model.Session, env map[string][]string, c config.Command) tea.Cmd {
+	return func() tea.Msg {
+		results := make(map[string]testResult, len(variants))
+		var mu sync.Mutex
+		var wg sync.WaitGroup
+		for _, s := range variants {
+			wg.Add(1)
+			go func(s model.Session) {
+				defer wg.Done()
+				var tail []string
+				start := time.Now()
+				err := setup.RunCommand(s.Path, c, env[s.Path], func(line string) {
+					tail = append(tail, strings.TrimPrefix(line, "  "))
+					if len(tail) > 8 {
+						tail = tail[1:]
+					}
+				})
+				if err != nil {
+					tail = append(tail, err.Error())
+				}
+				mu.Lock()
+				results[s.Path] = testResult{passed: err == nil, duration: time.Since(start), tail: tail}
+				mu.Unlock()
+			}(s)
+		}
+		wg.Wait()
+		return variantTestsMsg{group: group, results: results}
+	}
+}
+
+// pickWinnerCmd moves every variant but the winner to the trash and takes
+// the winner out of its group.
+func pickWinnerCmd(repoRoot string, winner model.Session, losers []model.Session) tea.Cmd {
+	return func() tea.Msg {
+		msg := winnerPickedMsg{winner: winner.Slug}
+		for _, s := range losers {
+			e, err := archive(repoRoot, s)
+			if e.ID != "" {
+				msg.archived = append(msg.archived, e)
+			}
+			if err != nil {
+				msg.err = fmt.Errorf("archive %s: %w", s.Slug, err)
+				return msg
+			}
+		}
+		msg.err = git.SetBranchVariant(repoRoot, winner.Branch, "")
+		return msg
+	}
+}
+
+// — variant state ———————————————————————————————————————————————————————————
+
+// variantsOf returns the sessions in a variant group.
+func (m Model) variantsOf(group string) []model.Session {
+	var out []model.Session
+	for _, s := range m.sessions {
+		if s.Variant == group {
+			out = append(out, s)
+		}
+	}
+	return out
+}
+
+func (m *Model) focusVariantField(f variantField) tea.Cmd {
+	m.variantField = f
+	m.nameInput.Blur()
+	m.baseInput.Blur()
+	m.countInput.Blur()
+	m.taskInput.Blur()
+	switch f {
+	case variantBase:
+		return m.baseInput.Focus()
+	case variantCount:
+		return m.countInput.Focus()
+	case variantTask:
+		return m.taskInput.Focus()
+	default:
+		return m.nameInput.Focus()
+	}
+}
+
+// resetVariants prepares an empty new-variants modal.
+func (m Model) resetVariants() (tea.Model, tea.Cmd) {
+	m.state = stateVariants
+	m.inputErr = ""
+	m.nameInput.Placeholder = "e.g. fix-flaky-checkout"
+	m.nameInput.Reset()
+	m.baseInput.Reset()
+	m.countInput.SetValue(strconv.Itoa(m.cfg.Variants.Count))
+	m.taskInput.Reset()
+	return m, m.focusVariantField(variantName)
+}
+
+func (m Model) updateVariants(msg tea.Msg) (tea.Model, tea.Cmd) {
+	if m.spawnRunning {
+		return m, nil
+	}
+	if key, ok := msg.(tea.KeyMsg); ok {
+		switch key.String() {
+		case "esc":
+			m.state = stateNormal
+			m.inputErr = ""
+			m.focusVariantField(variantName)
+			m.nameInput.Blur()
+			return m, nil
+		case "tab":
+			return m, m.focusVariantField((m.variantField + 1) % 4)
+		case "shift+tab":
+			return m, m.focusVariantField((m.variantField + 3) % 4)
+		case "ctrl+s":
+			return m.submitVariants()
+		case "enter":
+			if m.variantField != variantTask {
+				return m.submitVariants()
+			}
+		}
+	}
+	var cmd tea.Cmd
+	switch m.variantField {
+	case variantBase:
+		m.baseInput, cmd = m.baseInput.Update(msg)
+	case variantCount:
+		m.countInput, cmd = m.countInput.Update(msg)
+	case variantTask:
+		m.taskInput, cmd = m.taskInput.Update(msg)
+	default:
+		m.nameInput, cmd = m.nameInput.Update(msg)
+	}
+	return m, cmd
+}
+
+// submitVariants creates <name>-v1..vN from the same base, each running the
+// task in the background.
+func (m Model) submitVariants() (tea.Model, tea.Cmd) {
+	name := strings.TrimSpace(m.nameInput.Value())
+	n, err := strconv.Atoi(strings.TrimSpace(m.countInput.Value()))
+	task := strings.TrimSpace(m.taskInput.Value())
+	switch {
+	case m.repoRoot == "":
+		m.inputErr = "could not determine git repo root"
+	case name == "":
+		m.inputErr = "branch name cannot be empty"
+	case err != nil || n < 2 || n > maxVariants:
+		m.inputErr = fmt.Sprintf("variants must be between 2 and %d", maxVariants)
+	case task == "":
+		m.inputErr = "every variant needs the task"
+	default:
+		m.inputErr = ""
+		m.spawnRunning = true
+		tasks := spawn.ExpandVariants([]spawn.Task{{
+			Branch:   name,
+			Base:     strings.TrimSpace(m.baseInput.Value()),
+			Prompt:   task,
+			Variants: n,
+		}})
+		return m, spawnTasksCmd(m.repoRoot, m.cfg, tasks)
+	}
+	return m, nil
+}
+
+// openCompare shows the compare view for the variant group of s.
+func (m Model) openCompare(s *model.Session) (tea.Model, tea.Cmd) {
+	m.state = stateCompare
+	m.compareGroup = s.Variant
+	m.compareStats = nil
+	m.compareTests = nil
+	m.compareTesting = false
+	m.compareArmed = false
+	m.compareErr = ""
+	m.compareCursor = 0
+	variants := m.variantsOf(s.Variant)
+	for i, v := range variants {
+		if v.Path == s.Path {
+			m.compareCursor = i
+		}
+	}
+	return m, loadVariantStatsCmd(s.Variant, variants)
+}
+
+func (m Model) handleVariantsMsg(msg tea.Msg) (Model, tea.Cmd, bool) {
+	switch msg := msg.(type) {
+	case variantStatsMsg:
+		if msg.group == m.compareGroup {
+			m.compareStats = msg.stats
+		}
+		return m, nil, true
+
+	case variantTestsMsg:
+		if msg.group == m.compareGroup {
+			m.compareTests = msg.results
+			m.compareTesting = false
+		}
+		return m, nil, true
+
+	case winnerPickedMsg:
+		if len(msg.archived) > 0 && m.store != nil {
+			for i := range msg.archived {
+				msg.archived[i].Meta = m.takeMeta(msg.archived[i].Path)
+			}
+			m.store.Trash = append(m.store.Trash, msg.archived...)
+			if err := m.store.Save(); err != nil {
+				m.setNotice(err.Error(), true)
+			}
+		}
+		m.state = stateNormal
+		if msg.err != nil {
+			m.setNotice("pick winner: "+msg.err.Error(), true)
+		} else {
+			m.setNotice(fmt.Sprintf("kept %s, archived %d variant(s) to trash — T to restore", msg.winner, len(msg.archived)), false)
+		}
+		m.loading = true
+		return m, m.fetchSessions(), true
+	}
+	return m, nil, false
+}
+
+func (m Model) updateCompare(msg tea.Msg) (tea.Model, tea.Cmd) {
+	key, ok := msg.(tea.KeyMsg)
+	if !ok {
+		return m, nil
+	}
+	variants := m.variantsOf(m.compareGroup)
+	armed := m.compareArmed
+	m.compareArmed = false
+	switch key.String() {
+	case "esc", "q":
+		m.state = stateNormal
+	case "up", "k":
+		if m.compareCursor > 0 {
+			m.compareCursor--
+		}
+	case "down", "j":
+		if m.compareCursor < len(variants)-1 {
+			m.compareCursor++
+		}
+	case "f":
+		m.compareStats = nil
+		return m, loadVariantStatsCmd(m.compareGroup, variants)
+	case "t":
+		if strings.TrimSpace(m.cfg.Variants.TestCommand.Cmd) == "" {
+			m.compareErr = "no variants.test_command configured"
+			return m, nil
+		}
+		if m.compareTesting {
+			return m, nil
+		}
+		m.compareErr = ""
+		m.compareTesting = true
+		m.compareTests = nil
+		env := map[string][]string{}
+		for _, s := range variants {
+			env[s.Path] = m.portEnv(s.Path)
+		}
+		return m, testVariantsCmd(m.compareGroup, variants, env, m.cfg.Variants.TestCommand)
+	case "w":
+		if m.compareCursor >= len(variants) {
+			return m, nil
+		}
+		if !armed {
+			m.compareArmed = true
+			return m, nil
+		}
+		winner := variants[m.compareCursor]
+		var losers []model.Session
+		for i, v := range variants {
+			if i != m.compareCursor {
+				losers = append(losers, v)
+			}
+		}
+		return m, pickWinnerCmd(m.repoRoot, winner, losers)
+	}
+	return m, nil
+}
+
+// — variant rendering ———————————————————————————————————————————————————————
+
+// formatTokens abbreviates a token count, e.g. 1.2M or 35k.
+func formatTokens(n int) string {
+	switch {
+	case n >= 1_000_000:
+		return fmt.Sprintf("%.1fM", float64(n)/1_000_000)
+	case n >= 1_000:
+		return fmt.Sprintf("%dk", n/1_000)
+	default:
+		return strconv.Itoa(n)
+	}
+}
+
+func (m Model) renderVariantsOver(base string) string {
+	var b strings.Builder
+	b.WriteString(detailHeadStyle.Render("NEW VARIANTS") + "\n\n")
+	b.WriteString(labelStyle.Render("BRANCH NAME") + "\n")
+	b.WriteString(m.nameInput.View() + "\n\n")
+	b.WriteString(labelStyle.Render("BASE") + "\n")
+	b.WriteString(m.baseInput.View() + "\n\n")
+	b.WriteString(labelStyle.Render("VARIANTS") + "\n")
+	b.WriteString(m.countInput.View() + "\n\n")
+	b.WriteString(labelStyle.Render("TASK") + "\n")
+	b.WriteString(m.taskInput.View() + "\n")
+	if m.inputErr != "" {
+		b.WriteString("\n" + errStyle.Render(m.inputErr) + "\n")
+	}
+	if m.spawnRunning {
+		b.WriteString("\n" + warnStyle.Render(spinnerFrames[m.spinnerFrame]+" creating variants…"))
+	} else {
+		name := strings.TrimSpace(m.nameInput.Value())
+		if name == "" {
+			name = "<branch>"
+		}
+		b.WriteString("\n" + dimStyle.Render("creates "+name+"-v1…vN · same base and task · runs in background"))
+	}
+
+	modal := modalStyle.Render(b.String())
+	return lipgloss.Place(m.width, m.height, lipgloss.Center, lipgloss.Center, modal,
+		lipgloss.WithWhitespaceBackground(lipgloss.Color("0")),
+	)
+}
+
+func (m Model) renderCompare() string {
+	variants := m.variantsOf(m.compareGroup)
+
+	var head strings.Builder
+	head.WriteString(detailHeadStyle.Render("VARIANTS") + "  " + dimStyle.Render(strings.ToUpper(m.compareGroup)))
+	if len(variants) > 0 && variants[0].Base != "" {
+		head.WriteString("  " + dimStyle.Render("from "+variants[0].Base))
+	}
+	if m.compareErr != "" {
+		head.WriteString("  " + errStyle.Render(m.compareErr))
+	}
+
+	var b strings.Builder
+	if len(variants) == 0 {
+		b.WriteString(dimStyle.Render("NO VARIANTS LEFT IN THIS GROUP") + "\n")
+	} else {
+		b.WriteString(labelStyle.Render(fmt.Sprintf(" %-36s %-12s %-22s %-8s %-14s %s", "VARIANT", "STATUS", "DIFF", "COMMITS", "TOKENS", "TESTS")) + "\n")
+	}
+	for i, s := range variants {
+		var status string
+		switch {
+		case s.NeedsInput:
+			status = "▲ input"
+		case s.TmuxRunning:
+			status = "◆ active"
+		default:
+			status = "· idle"
+		}
+
+		diff, tokens := "…", "…"
+		if st, ok := m.compareStats[s.Path]; ok {
+			if st.diffErr != "" {
+				diff = "─"
+			} else {
+				diff = fmt.Sprintf("%d files +%d -%d", st.diff.Files, st.diff.Added, st.diff.Deleted)
+			}
+			if st.usageErr != "" || st.usage.Total() == 0 {
+				tokens = "─"
+			} else {
+				tokens = formatTokens(st.usage.Input+st.usage.CacheRead+st.usage.CacheCreation) + " / " + formatTokens(st.usage.Output)
+			}
+		}
+
+		tests := dimStyle.Render("─")
+		if m.compareTesting {
+			tests = warnStyle.Render(spinnerFrames[m.spinnerFrame] + " testing")
+		} else if r, ok := m.compareTests[s.Path]; ok {
+			d := r.duration.Round(time.Second).String()
+			if r.passed {
+				tests = okStyle.Render("◆ PASS " + d)
+			} else {
+				tests = errStyle.Render("✕ FAIL " + d)
+			}
+		}
+
+		line := fmt.Sprintf("%-36s %-12s %-22s %-8s %-14s ", s.Slug, status, diff, fmt.Sprintf("↑%d", s.Ahead), tokens)
+		if i == m.compareCursor {
+			line = labelStyle.Render("▌") + boldStyle.Render(line)
+		} else {
+			line = " " + line
+		}
+		b.WriteString(line + tests + "\n")
+	}
+
+	if m.compareCursor < len(variants) {
+		s := variants[m.compareCursor]
+		if st, ok := m.compareStats[s.Path]; ok && st.diffErr != "" {
+			b.WriteString("\n" + errStyle.Render("diff: "+st.diffErr) + "\n")
+		}
+		if r, ok := m.compareTests[s.Path]; ok && !r.passed {
+			b.WriteString("\n" + sectionSep("TEST OUTPUT", 60) + "\n")
+			for _, l := range r.tail {
+				b.WriteString(dimStyle.Render(l) + "\n")
+			}
+		}
+		if m.compareArmed {
+			b.WriteString("\n" + warnStyle.Render(fmt.Sprintf("▲ press w again to keep %s and archive the other %d", s.Slug, len(variants)-1)) + "\n")
+		}
+	}
+	b.WriteString("\n" + dimStyle.Render("tokens are input (incl. cache) / output, summed over every conversation in the worktree"))
+
+	return lipgloss.JoinVertical(lipgloss.Left,
+		lipgloss.NewStyle().Padding(1, 2, 0, 2).Render(head.String()),
+		lipgloss.NewStyle().Padding(1, 2, 0, 2).Height(m.height-4).Render(b.String()),
+		m.renderHelp(),
+	)
+}
@@ -10,6 +10,19 @@ import (
 )
 
 func main() {
+	if len(os.Args) > 1 && os.Args[1] == "spawn" {
+		os.Exit(runSpawn(os.Args[2:]))
+	}
+	if len(os.Args) > 1 && os.Args[1] == "next" {
+		os.Exit(runNext(os.Args[2:]))
+	}
+	if len(os.Args) > 1 && os.Args[1] == "pick" {
+		os.Exit(runPick(os.Args[2:]))
+	}
+	if len(os.Args) > 1 && os.Args[1] == "status" {
+		os.Exit(runStatus(os.Args[2:]))
+	}
+
 	p := tea.NewProgram(tui.New(), tea.WithAltScreen())
 	if _, err := p.Run(); err != nil {
 		fmt.Fprintf(os.Stderr, "error: %v\n", err)
@@ -0,0 +1,36 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+
+	"deckard/internal/tmux"
+)
+
+// runNext implements `deckard next`, which moves a tmux client to the next
+// Deckard session whose agent is waiting for input. It is bound to a key in
+// Deckard's tmux config, so triage works without leaving tmux.
+func runNext(args []string) int {
+	fs := flag.NewFlagSet("next", flag.ContinueOnError)
+	client := fs.String("client", "", "tmux client to switch (default: the current one)")
+	session := fs.String("session", "", "session the client is on; the search starts after it")
+	fs.Usage = func() {
+		fmt.Fprintln(fs.Output(), "usage: deckard next [--client name] [--session name]")
+		fs.PrintDefaults()
+	}
+	if err := fs.Parse(args); err != nil {
+		return 2
+	}
+
+	slug, ok := tmux.NextWaiting(*session)
+	if !ok || slug == *session {
+		tmux.DisplayMessage(*client, "no other session needs input")
+		return 0
+	}
+	if err := tmux.SwitchClient(*client, slug); err != nil {
+		fmt.Fprintf(os.Stderr, "error: %v\n", err)
+		return 1
+	}
+	return 0
+}
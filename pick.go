@@ -0,0 +1,32 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+
+	tea "github.com/charmbracelet/bubbletea"
+
+	"deckard/internal/tui"
+)
+
+// runPick implements `deckard pick`, the session switcher Deckard's tmux
+// config opens in a popup.
+func runPick(args []string) int {
+	fs := flag.NewFlagSet("pick", flag.ContinueOnError)
+	client := fs.String("client", "", "tmux client to switch (default: the current one)")
+	session := fs.String("session", "", "session the client is on")
+	fs.Usage = func() {
+		fmt.Fprintln(fs.Output(), "usage: deckard pick [--client name] [--session name]")
+		fs.PrintDefaults()
+	}
+	if err := fs.Parse(args); err != nil {
+		return 2
+	}
+
+	if _, err := tea.NewProgram(tui.NewPick(*client, *session)).Run(); err != nil {
+		fmt.Fprintf(os.Stderr, "error: %v\n", err)
+		return 1
+	}
+	return 0
+}
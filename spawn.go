@@ -0,0 +1,86 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+
+	"deckard/internal/config"
+	"deckard/internal/git"
+	"deckard/internal/spawn"
+	"deckard/internal/store"
+)
+
+// runSpawn implements `deckard spawn [-j N] tasks.yaml`.
+func runSpawn(args []string) int {
+	fs := flag.NewFlagSet("spawn", flag.ContinueOnError)
+	jobs := fs.Int("j", 0, "sessions to create in parallel (default from config)")
+	fs.Usage = func() {
+		fmt.Fprintln(fs.Output(), "usage: deckard spawn [-j N] tasks.yaml")
+		fs.PrintDefaults()
+	}
+	if err := fs.Parse(args); err != nil {
+		return 2
+	}
+	if fs.NArg() != 1 {
+		fs.Usage()
+		return 2
+	}
+
+	root, err := git.RepoRoot()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "error: %v\n", err)
+		return 1
+	}
+	cfg, err := config.Load(root)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "error: %v\n", err)
+		return 1
+	}
+	tasks, err := spawn.LoadTasks(fs.Arg(0))
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "error: %v\n", err)
+		return 1
+	}
+
+	n := *jobs
+	if n == 0 {
+		n = cfg.Spawn.Concurrency
+	}
+	fmt.Printf("spawning %d session(s), %d at a time…\n", len(tasks), n)
+
+	results := spawn.Run(root, cfg, tasks, n, func(r spawn.Result) {
+		switch {
+		case r.Started() && r.Stale != nil:
+			fmt.Printf("✓ %s (stale base: %v)\n", r.Slug, r.Stale)
+		case r.Started():
+			fmt.Printf("✓ %s\n", r.Slug)
+		default:
+			fmt.Printf("✕ %s: %v\n", r.Slug, r.Err)
+		}
+	})
+
+	st, err := store.Open(root)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "warning: %v\n", err)
+	}
+	failed := 0
+	for _, r := range results {
+		if !r.Started() {
+			failed++
+		}
+		if st != nil && r.Path != "" {
+			st.Remember(r.Path, r.Meta())
+		}
+	}
+	if st != nil {
+		if err := st.Save(); err != nil {
+			fmt.Fprintf(os.Stderr, "warning: %v\n", err)
+		}
+	}
+	fmt.Printf("\n%d started, %d failed\n", len(results)-failed, failed)
+	if failed > 0 {
+		return 1
+	}
+	return 0
+}
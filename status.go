@@ -0,0 +1,37 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+
+	"deckard/internal/tmux"
+)
+
+// runStatus implements `deckard status`, which prints how many other Deckard
+// sessions need input, formatted for the tmux status bar. It prints nothing
+// when none do.
+func runStatus(args []string) int {
+	fs := flag.NewFlagSet("status", flag.ContinueOnError)
+	session := fs.String("session", "", "session to leave out of the count")
+	if err := fs.Parse(args); err != nil {
+		return 2
+	}
+
+	sessions, _ := tmux.ListSessions()
+	var names []string
+	for _, s := range sessions {
+		if s.Name != *session {
+			names = append(names, s.Name)
+		}
+	}
+	n := 0
+	for _, w := range tmux.Waiting(names) {
+		if w {
+			n++
+		}
+	}
+	if n > 0 {
+		fmt.Printf("#[fg=colour214]▲ %d waiting  ", n)
+	}
+	return 0
+}